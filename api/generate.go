@@ -0,0 +1,5 @@
+// Package api reúne os contratos compartilhados entre os serviços. O código Go
+// é gerado a partir dos arquivos .proto com protoc-gen-go e protoc-gen-go-grpc.
+package api
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative temperature/v1/temperature.proto
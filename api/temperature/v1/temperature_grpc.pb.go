@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: temperature/v1/temperature.proto
+
+package temperaturev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TemperatureService_GetTemperature_FullMethodName = "/temperature.v1.TemperatureService/GetTemperature"
+)
+
+// TemperatureServiceClient is the client API for TemperatureService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TemperatureService é a versão gRPC da consulta de temperatura por CEP do
+// serviço B.
+type TemperatureServiceClient interface {
+	// GetTemperature devolve a temperatura atual da cidade do CEP. Os erros
+	// trazem um google.rpc.ErrorInfo com o código do envelope de erro HTTP em
+	// reason e, quando existem, os detalhes em um google.protobuf.Struct e o
+	// tempo de espera em um google.rpc.RetryInfo.
+	GetTemperature(ctx context.Context, in *GetTemperatureRequest, opts ...grpc.CallOption) (*GetTemperatureResponse, error)
+}
+
+type temperatureServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTemperatureServiceClient(cc grpc.ClientConnInterface) TemperatureServiceClient {
+	return &temperatureServiceClient{cc}
+}
+
+func (c *temperatureServiceClient) GetTemperature(ctx context.Context, in *GetTemperatureRequest, opts ...grpc.CallOption) (*GetTemperatureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTemperatureResponse)
+	err := c.cc.Invoke(ctx, TemperatureService_GetTemperature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TemperatureServiceServer is the server API for TemperatureService service.
+// All implementations must embed UnimplementedTemperatureServiceServer
+// for forward compatibility.
+//
+// TemperatureService é a versão gRPC da consulta de temperatura por CEP do
+// serviço B.
+type TemperatureServiceServer interface {
+	// GetTemperature devolve a temperatura atual da cidade do CEP. Os erros
+	// trazem um google.rpc.ErrorInfo com o código do envelope de erro HTTP em
+	// reason e, quando existem, os detalhes em um google.protobuf.Struct e o
+	// tempo de espera em um google.rpc.RetryInfo.
+	GetTemperature(context.Context, *GetTemperatureRequest) (*GetTemperatureResponse, error)
+	mustEmbedUnimplementedTemperatureServiceServer()
+}
+
+// UnimplementedTemperatureServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTemperatureServiceServer struct{}
+
+func (UnimplementedTemperatureServiceServer) GetTemperature(context.Context, *GetTemperatureRequest) (*GetTemperatureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTemperature not implemented")
+}
+func (UnimplementedTemperatureServiceServer) mustEmbedUnimplementedTemperatureServiceServer() {}
+func (UnimplementedTemperatureServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeTemperatureServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TemperatureServiceServer will
+// result in compilation errors.
+type UnsafeTemperatureServiceServer interface {
+	mustEmbedUnimplementedTemperatureServiceServer()
+}
+
+func RegisterTemperatureServiceServer(s grpc.ServiceRegistrar, srv TemperatureServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTemperatureServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TemperatureService_ServiceDesc, srv)
+}
+
+func _TemperatureService_GetTemperature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTemperatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemperatureServiceServer).GetTemperature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TemperatureService_GetTemperature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemperatureServiceServer).GetTemperature(ctx, req.(*GetTemperatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TemperatureService_ServiceDesc is the grpc.ServiceDesc for TemperatureService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TemperatureService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "temperature.v1.TemperatureService",
+	HandlerType: (*TemperatureServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTemperature",
+			Handler:    _TemperatureService_GetTemperature_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "temperature/v1/temperature.proto",
+}
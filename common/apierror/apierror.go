@@ -0,0 +1,61 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// Code é o identificador estável do erro, usado pelos clientes para decidir
+// como reagir sem interpretar a mensagem.
+// Os dois serviços usam o mesmo envelope, e o serviço A repassa os erros do
+// serviço B sem alteração.
+type Code string
+
+const (
+	CodeInvalidRequest      Code = "INVALID_REQUEST"
+	CodeInvalidZipCode      Code = "INVALID_ZIPCODE"
+	CodeZipCodeNotFound     Code = "ZIPCODE_NOT_FOUND"
+	CodeCityNotFound        Code = "CITY_NOT_FOUND"
+	CodeZipCodeLookupFailed Code = "ZIPCODE_LOOKUP_FAILED"
+	CodeWeatherLookupFailed Code = "WEATHER_LOOKUP_FAILED"
+	CodeUpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	CodeUpstreamTimeout     Code = "UPSTREAM_TIMEOUT"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeInternal            Code = "INTERNAL_ERROR"
+)
+
+// Error é o envelope JSON devolvido em todas as respostas de erro.
+type Error struct {
+	Code    Code                   `json:"code"`
+	Message string                 `json:"message"`
+	TraceID string                 `json:"trace_id,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetail acrescenta uma informação complementar ao erro.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Write serializa o erro com o status informado, preenchendo o trace_id a
+// partir do span ativo em ctx para que o erro possa ser localizado no Zipkin.
+func Write(ctx context.Context, w http.ResponseWriter, status int, e *Error) {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		e.TraceID = spanCtx.TraceID().String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(e)
+}
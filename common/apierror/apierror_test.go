@@ -0,0 +1,56 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      *Error
+		expected Error
+	}{
+		{
+			name:     "without span",
+			ctx:      context.Background(),
+			err:      New(CodeInvalidZipCode, "invalid zipcode"),
+			expected: Error{Code: CodeInvalidZipCode, Message: "invalid zipcode"},
+		},
+		{
+			name: "with span and details",
+			ctx:  trace.ContextWithSpanContext(context.Background(), spanCtx),
+			err:  New(CodeUpstreamUnavailable, "viacep is temporarily unavailable").WithDetail("upstream", "viacep"),
+			expected: Error{
+				Code:    CodeUpstreamUnavailable,
+				Message: "viacep is temporarily unavailable",
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				Details: map[string]interface{}{"upstream": "viacep"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Write(tt.ctx, w, http.StatusUnprocessableEntity, tt.err)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var got Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WriteJSON serializa response como corpo de uma resposta 200.
+func WriteJSON(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Includes indica se o cliente pediu o campo opcional name via
+// ?include=, que aceita valores separados por vírgula.
+func Includes(r *http.Request, name string) bool {
+	for _, value := range r.URL.Query()["include"] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.TrimSpace(field) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package httpapi
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncludes(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		expected bool
+	}{
+		{name: "absent", target: "/01001000", expected: false},
+		{name: "single value", target: "/01001000?include=address", expected: true},
+		{name: "comma separated", target: "/01001000?include=forecast,%20address", expected: true},
+		{name: "repeated parameter", target: "/01001000?include=forecast&include=address", expected: true},
+		{name: "other field", target: "/01001000?include=forecast", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Includes(httptest.NewRequest("GET", tt.target, nil), "address"))
+		})
+	}
+}
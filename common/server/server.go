@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// flushShare é a fração do prazo de encerramento reservada ao envio da
+// telemetria, que só começa depois que as requisições em andamento terminam.
+const flushShare = 6
+
+// Start atende as requisições de server em segundo plano. Se o servidor parar
+// sem ter sido encerrado por Shutdown, o erro é enviado em errs.
+func Start(server *http.Server, errs chan<- error) {
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+}
+
+// Shutdown para de aceitar conexões e espera as requisições em andamento até
+// ctx expirar. Depois disso, as conexões restantes são fechadas.
+func Shutdown(ctx context.Context, server *http.Server) error {
+	if err := server.Shutdown(ctx); err != nil {
+		return errors.Join(err, server.Close())
+	}
+	return nil
+}
+
+// ShutdownContexts divide um único prazo de encerramento, contado a partir de
+// agora: as requisições em andamento têm até drain, que expira antes para
+// garantir uma parte de timeout ao envio da telemetria, e tudo termina em
+// flush.
+func ShutdownContexts(timeout time.Duration) (drain, flush context.Context, cancel context.CancelFunc) {
+	flush, cancelFlush := context.WithTimeout(context.Background(), timeout)
+	drain, cancelDrain := context.WithTimeout(flush, timeout-timeout/flushShare)
+	return drain, flush, func() {
+		cancelDrain()
+		cancelFlush()
+	}
+}
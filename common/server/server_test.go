@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownContexts(t *testing.T) {
+	start := time.Now()
+	drain, flush, cancel := ShutdownContexts(30 * time.Second)
+	defer cancel()
+
+	drainDeadline, ok := drain.Deadline()
+	assert.True(t, ok)
+	flushDeadline, ok := flush.Deadline()
+	assert.True(t, ok)
+
+	assert.WithinDuration(t, start.Add(30*time.Second), flushDeadline, time.Second)
+	assert.WithinDuration(t, start.Add(25*time.Second), drainDeadline, time.Second)
+
+	cancel()
+	assert.ErrorIs(t, drain.Err(), context.Canceled)
+	assert.ErrorIs(t, flush.Err(), context.Canceled)
+}
+
+func TestStart(t *testing.T) {
+	errs := make(chan error, 1)
+	Start(&http.Server{Addr: "invalid:address:1"}, errs)
+
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("listen error was not reported")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	server := &http.Server{Addr: "127.0.0.1:0"}
+	errs := make(chan error, 1)
+	Start(server, errs)
+
+	assert.NoError(t, Shutdown(context.Background(), server))
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected server error: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,62 @@
+package zipcode
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"strings"
+)
+
+const instrumentationName = "github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+
+// validationFailures vem do provider global, que passa a usar o provider
+// registrado por telemetry.Setup mesmo que ele seja configurado depois.
+var validationFailures = newValidationFailures(otel.GetMeterProvider())
+
+func newValidationFailures(provider metric.MeterProvider) metric.Int64Counter {
+	counter, err := provider.Meter(instrumentationName).Int64Counter(
+		"zipcode.validation.failures",
+		metric.WithDescription("Number of zip codes rejected by validation."),
+		metric.WithUnit("{failure}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}
+
+// RecordValidationFailure conta um CEP rejeitado pela validação, com o
+// motivo da rejeição.
+func RecordValidationFailure(ctx context.Context, reason string) {
+	recordValidationFailure(ctx, validationFailures, reason)
+}
+
+func recordValidationFailure(ctx context.Context, counter metric.Int64Counter, reason string) {
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+// Validate devolve apenas os dígitos do CEP, que deve ter exatamente 8.
+func Validate(zipCode string) (string, error) {
+	cleanZip := ""
+	for _, char := range zipCode {
+		if char >= '0' && char <= '9' {
+			cleanZip += string(char)
+		}
+	}
+	if len(cleanZip) != 8 {
+		return "", fmt.Errorf("invalid zipcode: must contain exactly 8 digits")
+	}
+	return cleanZip, nil
+}
+
+// Format devolve o CEP no formato 00000-000, independente do formato usado
+// pelo provedor.
+func Format(zipCode string) string {
+	digits := strings.ReplaceAll(zipCode, "-", "")
+	if len(digits) != 8 {
+		return zipCode
+	}
+	return digits[:5] + "-" + digits[5:]
+}
@@ -0,0 +1,113 @@
+package zipcode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		zipCode   string
+		want      string
+		expectErr bool
+	}{
+		{
+			name:      "valid 8 digits",
+			zipCode:   "12345678",
+			want:      "12345678",
+			expectErr: false,
+		},
+		{
+			name:      "contains letters",
+			zipCode:   "1234abcd",
+			want:      "",
+			expectErr: true,
+		},
+		{
+			name:      "too short",
+			zipCode:   "12345",
+			want:      "",
+			expectErr: true,
+		},
+		{
+			name:      "too long",
+			zipCode:   "123456789",
+			want:      "",
+			expectErr: true,
+		},
+		{
+			name:      "contains special chars",
+			zipCode:   "1234#678",
+			want:      "",
+			expectErr: true,
+		},
+		{
+			name:      "contains dash char",
+			zipCode:   "12345-678",
+			want:      "12345678",
+			expectErr: false,
+		},
+		{
+			name:      "empty string",
+			zipCode:   "",
+			want:      "",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.zipCode)
+
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		zipCode  string
+		expected string
+	}{
+		{name: "digits only", zipCode: "01001000", expected: "01001-000"},
+		{name: "already formatted", zipCode: "01001-000", expected: "01001-000"},
+		{name: "unexpected length is kept", zipCode: "0100100", expected: "0100100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Format(tt.zipCode))
+		})
+	}
+}
+
+func TestRecordValidationFailure(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	counter := newValidationFailures(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	recordValidationFailure(ctx, counter, "invalid_format")
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(ctx, &rm))
+	assert.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, "zipcode.validation.failures", rm.ScopeMetrics[0].Metrics[0].Name)
+
+	failures := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	assert.Equal(t, int64(1), failures.DataPoints[0].Value)
+	reason, _ := failures.DataPoints[0].Attributes.Value(attribute.Key("reason"))
+	assert.Equal(t, "invalid_format", reason.AsString())
+}
@@ -2,58 +2,114 @@ package main
 
 import (
 	"context"
+	"fmt"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/server"
+	"github.com/AndreD23/goexpert-labs-otel/servicea/configs"
+	"github.com/AndreD23/goexpert-labs-otel/servicea/internal/balancer"
 	"github.com/AndreD23/goexpert-labs-otel/servicea/internal/handlers"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
 	"github.com/go-chi/chi/v5"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/zipkin"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	shutdown, err := initTracer()
+	config := configs.NewConfig()
+	shutdown, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv("servicea"))
 	if err != nil {
 		log.Fatal("Init Provider error: ", err)
 	}
-	defer func() {
-		if err := shutdown(ctx); err != nil {
-			log.Fatal("failed to shutdown TracerProvider: %w", err)
-		}
-	}()
 
-	handler := handlers.New()
+	client, err := config.ServiceBClient()
+	if err != nil {
+		log.Fatal("Init servicea client error: ", err)
+	}
+	lb, err := newBalancer(ctx, config, client.Transport)
+	if err != nil {
+		log.Fatal("Init serviceb balancer error: ", err)
+	}
+	client.Transport = lb
+	handler := handlers.New(client, config.ServiceBURL)
+	if config.ServiceBTransport == "grpc" {
+		conn, err := config.ServiceBGRPCConn()
+		if err != nil {
+			log.Fatal("Init serviceb grpc client error: ", err)
+		}
+		defer conn.Close()
+		handler.WithGRPCClient(temperaturev1.NewTemperatureServiceClient(conn))
+	}
 
 	r := chi.NewRouter()
+	r.Use(telemetry.HTTPMetrics())
+	r.Handle("/metrics", telemetry.MetricsHandler())
 	r.Post("/", handler.HandleZipCodeInput)
-	http.ListenAndServe(":8081", r)
+	r.Post("/v2", handler.HandleZipCodeInputV2)
+	r.Post("/batch", handler.HandleBatchInput)
+	r.Post("/forecast", handler.HandleForecastInput)
+
+	httpServer := &http.Server{
+		Addr:              config.ListenAddr,
+		Handler:           r,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+	serverErr := make(chan error, 1)
+	server.Start(httpServer, serverErr)
+	var failed bool
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		log.Print(err)
+		failed = true
+	}
+
+	// SHUTDOWN_TIMEOUT é o prazo de todo o encerramento, contado a partir do
+	// sinal: primeiro as requisições em andamento, depois a telemetria
+	log.Print("shutting down, draining in-flight requests")
+	drainCtx, flushCtx, cancelShutdown := server.ShutdownContexts(config.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(drainCtx, httpServer); err != nil {
+		log.Printf("http server shutdown error: %v", err)
+	}
+	if err := shutdown(flushCtx); err != nil {
+		log.Printf("failed to shutdown telemetry: %v", err)
+	}
+	if failed {
+		os.Exit(1)
+	}
 }
 
-func initTracer() (func(ctx context.Context) error, error) {
-	traceExporter, err := zipkin.New("http://zipkin:9411/api/v2/spans")
+// newBalancer distribui as chamadas entre as instâncias do serviço B,
+// descobertas de acordo com SERVICEB_DISCOVERY.
+func newBalancer(ctx context.Context, config *configs.Config, transport http.RoundTripper) (*balancer.Balancer, error) {
+	lb, err := balancer.New(transport, config.BalancerSettings())
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName("servicea"),
-		)),
-		sdktrace.WithSpanProcessor(bsp),
-	)
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
-
-	return tracerProvider.Shutdown, nil
+		return nil, err
+	}
+
+	switch config.ServiceBDiscovery {
+	case "static":
+		lb.SetBackends(config.ServiceBBackends())
+	case "dns":
+		err = lb.Watch(ctx, balancer.DNS(net.DefaultResolver, config.ServiceBBackends()[0]), config.ServiceBDNSRefresh)
+	case "dns-srv":
+		err = lb.Watch(ctx, balancer.SRV(net.DefaultResolver, config.ServiceBBackends()[0].Scheme, config.ServiceBSRVName), config.ServiceBDNSRefresh)
+	default:
+		err = fmt.Errorf("unknown serviceb discovery %q", config.ServiceBDiscovery)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("serviceb backends: %v", lb.Backends())
+	return lb, nil
 }
@@ -0,0 +1,292 @@
+package configs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/servicea/internal/balancer"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var config *Config
+
+type Config struct {
+	ListenAddr              string        `mapstructure:"HTTP_LISTEN_ADDR"`
+	ReadTimeout             time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout       time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout            time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout             time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout         time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ServiceBURL             string        `mapstructure:"SERVICEB_URL"`
+	ServiceBURLs            []string      `mapstructure:"SERVICEB_URLS"`
+	ServiceBDiscovery       string        `mapstructure:"SERVICEB_DISCOVERY"`
+	ServiceBSRVName         string        `mapstructure:"SERVICEB_SRV_NAME"`
+	ServiceBDNSRefresh      time.Duration `mapstructure:"SERVICEB_DNS_REFRESH"`
+	ServiceBLBPolicy        string        `mapstructure:"SERVICEB_LB_POLICY"`
+	ServiceBEjectFailures   int           `mapstructure:"SERVICEB_EJECT_FAILURES"`
+	ServiceBEjectDuration   time.Duration `mapstructure:"SERVICEB_EJECT_DURATION"`
+	ServiceBEjectStatuses   []int         `mapstructure:"SERVICEB_EJECT_STATUSES"`
+	ServiceBTimeout         time.Duration `mapstructure:"SERVICEB_TIMEOUT"`
+	ServiceBDialTimeout     time.Duration `mapstructure:"SERVICEB_DIAL_TIMEOUT"`
+	ServiceBTLSTimeout      time.Duration `mapstructure:"SERVICEB_TLS_HANDSHAKE_TIMEOUT"`
+	ServiceBIdleConnTimeout time.Duration `mapstructure:"SERVICEB_IDLE_CONN_TIMEOUT"`
+	ServiceBMaxIdleConns    int           `mapstructure:"SERVICEB_MAX_IDLE_CONNS"`
+	ServiceBMaxIdlePerHost  int           `mapstructure:"SERVICEB_MAX_IDLE_CONNS_PER_HOST"`
+	ServiceBMaxConnsPerHost int           `mapstructure:"SERVICEB_MAX_CONNS_PER_HOST"`
+	ServiceBTLSCAFile       string        `mapstructure:"SERVICEB_TLS_CA_FILE"`
+	ServiceBTLSCertFile     string        `mapstructure:"SERVICEB_TLS_CERT_FILE"`
+	ServiceBTLSKeyFile      string        `mapstructure:"SERVICEB_TLS_KEY_FILE"`
+	ServiceBTLSServerName   string        `mapstructure:"SERVICEB_TLS_SERVER_NAME"`
+	ServiceBTLSSkipVerify   bool          `mapstructure:"SERVICEB_TLS_INSECURE_SKIP_VERIFY"`
+	ServiceBTransport       string        `mapstructure:"SERVICEB_TRANSPORT"`
+	ServiceBGRPCAddr        string        `mapstructure:"SERVICEB_GRPC_ADDR"`
+	ServiceBGRPCTLS         bool          `mapstructure:"SERVICEB_GRPC_TLS"`
+}
+
+func NewConfig() *Config {
+	return config
+}
+
+func init() {
+	var err error
+	config, err = loadConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Erro ao carregar configurações: %v", err))
+	}
+}
+
+func loadConfig() (*Config, error) {
+	viper.SetConfigType("env")
+	viper.AddConfigPath(".")
+
+	// Habilita o carregamento de variáveis de ambiente
+	viper.AutomaticEnv()
+
+	// Tenta ler o arquivo .env, mas ignora se não existir
+	viper.SetConfigFile(".env")
+	if err := viper.ReadInConfig(); err != nil {
+		fmt.Println("Arquivo .env não encontrado. Usando variáveis de ambiente.")
+	}
+
+	// Valores padrão, compatíveis com o docker-compose
+	viper.SetDefault("HTTP_LISTEN_ADDR", ":8081")
+	viper.SetDefault("HTTP_READ_TIMEOUT", 10*time.Second)
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", 5*time.Second)
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", 120*time.Second)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("SERVICEB_URL", "http://appb:8080")
+	viper.SetDefault("SERVICEB_URLS", "")
+	viper.SetDefault("SERVICEB_DISCOVERY", "static")
+	viper.SetDefault("SERVICEB_SRV_NAME", "")
+	viper.SetDefault("SERVICEB_DNS_REFRESH", 30*time.Second)
+	viper.SetDefault("SERVICEB_LB_POLICY", "round_robin")
+	viper.SetDefault("SERVICEB_EJECT_FAILURES", 5)
+	viper.SetDefault("SERVICEB_EJECT_DURATION", 30*time.Second)
+	viper.SetDefault("SERVICEB_EJECT_STATUSES", "502")
+	viper.SetDefault("SERVICEB_TIMEOUT", 10*time.Second)
+	viper.SetDefault("SERVICEB_DIAL_TIMEOUT", 3*time.Second)
+	viper.SetDefault("SERVICEB_TLS_HANDSHAKE_TIMEOUT", 5*time.Second)
+	viper.SetDefault("SERVICEB_IDLE_CONN_TIMEOUT", 90*time.Second)
+	viper.SetDefault("SERVICEB_MAX_IDLE_CONNS", 100)
+	viper.SetDefault("SERVICEB_MAX_IDLE_CONNS_PER_HOST", 10)
+	viper.SetDefault("SERVICEB_MAX_CONNS_PER_HOST", 0)
+	viper.SetDefault("SERVICEB_TLS_CA_FILE", "")
+	viper.SetDefault("SERVICEB_TLS_CERT_FILE", "")
+	viper.SetDefault("SERVICEB_TLS_KEY_FILE", "")
+	viper.SetDefault("SERVICEB_TLS_SERVER_NAME", "")
+	viper.SetDefault("SERVICEB_TLS_INSECURE_SKIP_VERIFY", false)
+	viper.SetDefault("SERVICEB_TRANSPORT", "http")
+	viper.SetDefault("SERVICEB_GRPC_ADDR", "dns:///appb:50051")
+	viper.SetDefault("SERVICEB_GRPC_TLS", false)
+
+	config := &Config{}
+	config.ListenAddr = viper.GetString("HTTP_LISTEN_ADDR")
+	config.ReadTimeout = viper.GetDuration("HTTP_READ_TIMEOUT")
+	config.ReadHeaderTimeout = viper.GetDuration("HTTP_READ_HEADER_TIMEOUT")
+	config.WriteTimeout = viper.GetDuration("HTTP_WRITE_TIMEOUT")
+	config.IdleTimeout = viper.GetDuration("HTTP_IDLE_TIMEOUT")
+	config.ShutdownTimeout = viper.GetDuration("SHUTDOWN_TIMEOUT")
+	config.ServiceBURL = strings.TrimRight(viper.GetString("SERVICEB_URL"), "/")
+	for _, rawURL := range strings.Split(viper.GetString("SERVICEB_URLS"), ",") {
+		if rawURL = strings.TrimSpace(rawURL); rawURL != "" {
+			config.ServiceBURLs = append(config.ServiceBURLs, strings.TrimRight(rawURL, "/"))
+		}
+	}
+	config.ServiceBDiscovery = viper.GetString("SERVICEB_DISCOVERY")
+	config.ServiceBSRVName = viper.GetString("SERVICEB_SRV_NAME")
+	config.ServiceBDNSRefresh = viper.GetDuration("SERVICEB_DNS_REFRESH")
+	config.ServiceBLBPolicy = viper.GetString("SERVICEB_LB_POLICY")
+	config.ServiceBEjectFailures = viper.GetInt("SERVICEB_EJECT_FAILURES")
+	config.ServiceBEjectDuration = viper.GetDuration("SERVICEB_EJECT_DURATION")
+	for _, rawStatus := range strings.Split(viper.GetString("SERVICEB_EJECT_STATUSES"), ",") {
+		if rawStatus = strings.TrimSpace(rawStatus); rawStatus == "" {
+			continue
+		}
+		status, err := strconv.Atoi(rawStatus)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("SERVICEB_EJECT_STATUSES deve conter apenas status HTTP: %q", rawStatus)
+		}
+		config.ServiceBEjectStatuses = append(config.ServiceBEjectStatuses, status)
+	}
+	config.ServiceBTimeout = viper.GetDuration("SERVICEB_TIMEOUT")
+	config.ServiceBDialTimeout = viper.GetDuration("SERVICEB_DIAL_TIMEOUT")
+	config.ServiceBTLSTimeout = viper.GetDuration("SERVICEB_TLS_HANDSHAKE_TIMEOUT")
+	config.ServiceBIdleConnTimeout = viper.GetDuration("SERVICEB_IDLE_CONN_TIMEOUT")
+	config.ServiceBMaxIdleConns = viper.GetInt("SERVICEB_MAX_IDLE_CONNS")
+	config.ServiceBMaxIdlePerHost = viper.GetInt("SERVICEB_MAX_IDLE_CONNS_PER_HOST")
+	config.ServiceBMaxConnsPerHost = viper.GetInt("SERVICEB_MAX_CONNS_PER_HOST")
+	config.ServiceBTLSCAFile = viper.GetString("SERVICEB_TLS_CA_FILE")
+	config.ServiceBTLSCertFile = viper.GetString("SERVICEB_TLS_CERT_FILE")
+	config.ServiceBTLSKeyFile = viper.GetString("SERVICEB_TLS_KEY_FILE")
+	config.ServiceBTLSServerName = viper.GetString("SERVICEB_TLS_SERVER_NAME")
+	config.ServiceBTLSSkipVerify = viper.GetBool("SERVICEB_TLS_INSECURE_SKIP_VERIFY")
+	config.ServiceBTransport = viper.GetString("SERVICEB_TRANSPORT")
+	config.ServiceBGRPCAddr = viper.GetString("SERVICEB_GRPC_ADDR")
+	config.ServiceBGRPCTLS = viper.GetBool("SERVICEB_GRPC_TLS")
+
+	// Validação das configurações obrigatórias
+	if !isServiceURL(config.ServiceBURL) {
+		return nil, fmt.Errorf("SERVICEB_URL deve ser uma URL http(s) absoluta: %q", config.ServiceBURL)
+	}
+	for _, rawURL := range config.ServiceBURLs {
+		if !isServiceURL(rawURL) {
+			return nil, fmt.Errorf("SERVICEB_URLS deve conter apenas URLs http(s) absolutas: %q", rawURL)
+		}
+		// O balanceador usa apenas o esquema e o host de cada instância
+		if u, _ := url.Parse(rawURL); u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("SERVICEB_URLS não aceita caminho, query ou fragmento; use SERVICEB_URL para o caminho base: %q", rawURL)
+		}
+	}
+	if config.ServiceBTransport != "http" && config.ServiceBTransport != "grpc" {
+		return nil, fmt.Errorf("SERVICEB_TRANSPORT deve ser http ou grpc: %q", config.ServiceBTransport)
+	}
+	if config.ServiceBDiscovery == "dns-srv" && config.ServiceBSRVName == "" {
+		return nil, fmt.Errorf("SERVICEB_SRV_NAME é obrigatória quando SERVICEB_DISCOVERY=dns-srv")
+	}
+	if (config.ServiceBTLSCertFile == "") != (config.ServiceBTLSKeyFile == "") {
+		return nil, fmt.Errorf("SERVICEB_TLS_CERT_FILE e SERVICEB_TLS_KEY_FILE devem ser informados juntos")
+	}
+
+	return config, nil
+}
+
+func isServiceURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ServiceBBackends devolve as instâncias estáticas do serviço B. Sem
+// SERVICEB_URLS, a única instância é SERVICEB_URL.
+func (c *Config) ServiceBBackends() []*url.URL {
+	rawURLs := c.ServiceBURLs
+	if len(rawURLs) == 0 {
+		rawURLs = []string{c.ServiceBURL}
+	}
+	urls := make([]*url.URL, 0, len(rawURLs))
+	for _, rawURL := range rawURLs {
+		// As URLs já foram validadas em loadConfig
+		u, _ := url.Parse(rawURL)
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+func (c *Config) BalancerSettings() balancer.Settings {
+	return balancer.Settings{
+		Policy:           balancer.Policy(c.ServiceBLBPolicy),
+		MaxFailures:      c.ServiceBEjectFailures,
+		EjectionDuration: c.ServiceBEjectDuration,
+		EjectStatuses:    c.ServiceBEjectStatuses,
+	}
+}
+
+// ServiceBClient monta o cliente HTTP usado nas chamadas ao serviço B, com os
+// timeouts, o pool de conexões e o TLS configurados.
+func (c *Config) ServiceBClient() (*http.Client, error) {
+	tlsConfig, err := c.serviceBTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   c.ServiceBDialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: c.ServiceBTLSTimeout,
+		IdleConnTimeout:     c.ServiceBIdleConnTimeout,
+		MaxIdleConns:        c.ServiceBMaxIdleConns,
+		MaxIdleConnsPerHost: c.ServiceBMaxIdlePerHost,
+		MaxConnsPerHost:     c.ServiceBMaxConnsPerHost,
+	}
+	return &http.Client{Transport: transport, Timeout: c.ServiceBTimeout}, nil
+}
+
+// ServiceBGRPCConn abre a conexão gRPC com o serviço B. O stats handler do
+// otelgrpc propaga o contexto do trace, e as chamadas são distribuídas entre
+// os endereços resolvidos para SERVICEB_GRPC_ADDR.
+func (c *Config) ServiceBGRPCConn() (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if c.ServiceBGRPCTLS {
+		tlsConfig, err := c.serviceBTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	return grpc.NewClient(c.ServiceBGRPCAddr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+	)
+}
+
+func (c *Config) serviceBTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServiceBTLSServerName,
+		InsecureSkipVerify: c.ServiceBTLSSkipVerify,
+	}
+	// Com a descoberta por DNS os backends são IPs, e o certificado continua
+	// sendo validado pelo nome do serviço
+	if tlsConfig.ServerName == "" && c.ServiceBDiscovery == "dns" {
+		tlsConfig.ServerName = c.ServiceBBackends()[0].Hostname()
+	}
+
+	if c.ServiceBTLSCAFile != "" {
+		pem, err := os.ReadFile(c.ServiceBTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read SERVICEB_TLS_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("SERVICEB_TLS_CA_FILE has no valid certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Certificado do cliente, para serviços B que exigem mTLS
+	if c.ServiceBTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ServiceBTLSCertFile, c.ServiceBTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load servicea client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
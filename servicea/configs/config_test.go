@@ -0,0 +1,65 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig_ServiceBURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		urls      string
+		expected  []string
+		expectErr bool
+	}{
+		{name: "hosts only", urls: "http://b1:8080, http://b2:8080/", expected: []string{"http://b1:8080", "http://b2:8080"}},
+		{name: "path is rejected", urls: "http://b1:8080,http://b2:8080/api", expectErr: true},
+		{name: "query is rejected", urls: "http://b1:8080?x=1", expectErr: true},
+		{name: "relative url is rejected", urls: "b1:8080", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SERVICEB_URLS", tt.urls)
+
+			config, err := loadConfig()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, config.ServiceBURLs)
+		})
+	}
+}
+
+func TestLoadConfig_ServiceBEjectStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  string
+		expected  []int
+		expectErr bool
+	}{
+		{name: "default", expected: []int{502}},
+		{name: "list", statuses: "502, 503", expected: []int{502, 503}},
+		{name: "not a number", statuses: "5xx", expectErr: true},
+		{name: "out of range", statuses: "1000", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.statuses != "" {
+				t.Setenv("SERVICEB_EJECT_STATUSES", tt.statuses)
+			}
+
+			config, err := loadConfig()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, config.ServiceBEjectStatuses)
+		})
+	}
+}
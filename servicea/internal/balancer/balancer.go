@@ -0,0 +1,223 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Policy define como o próximo backend é escolhido.
+type Policy string
+
+const (
+	RoundRobin       Policy = "round_robin"
+	LeastOutstanding Policy = "least_outstanding"
+)
+
+// ErrNoBackends é devolvido quando nenhum backend foi configurado ou
+// descoberto.
+var ErrNoBackends = errors.New("no serviceb backends available")
+
+type Settings struct {
+	Policy Policy
+	// MaxFailures é o número de falhas consecutivas que tira um backend de
+	// rotação por EjectionDuration. Zero desabilita a ejeção.
+	MaxFailures      int
+	EjectionDuration time.Duration
+	// EjectStatuses são os status HTTP que contam como falha do backend, além
+	// dos erros de rede. Um 503 ou 504 do serviço B costuma refletir a falha
+	// de um provedor externo, e não da instância.
+	EjectStatuses []int
+}
+
+type backend struct {
+	url          *url.URL
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+}
+
+// Balancer distribui as requisições entre as instâncias do serviço B,
+// trocando o esquema e o host da URL pelos do backend escolhido. Backends que
+// falham seguidamente (erro de rede ou um dos EjectStatuses) são ejetados
+// temporariamente.
+type Balancer struct {
+	transport http.RoundTripper
+	settings  Settings
+	now       func() time.Time
+
+	mu       sync.Mutex
+	backends []*backend
+	cursor   int
+}
+
+func New(next http.RoundTripper, settings Settings) (*Balancer, error) {
+	switch settings.Policy {
+	case RoundRobin, LeastOutstanding:
+	default:
+		return nil, fmt.Errorf("unknown load balancing policy %q", settings.Policy)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Balancer{
+		transport: next,
+		settings:  settings,
+		now:       time.Now,
+	}, nil
+}
+
+// SetBackends troca a lista de backends, preservando o estado dos que
+// continuam nela.
+func (b *Balancer) SetBackends(urls []*url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := make(map[string]*backend, len(b.backends))
+	for _, be := range b.backends {
+		current[be.url.String()] = be
+	}
+	backends := make([]*backend, 0, len(urls))
+	for _, u := range urls {
+		if be, ok := current[u.String()]; ok {
+			backends = append(backends, be)
+			continue
+		}
+		backends = append(backends, &backend{url: u})
+	}
+	b.backends = backends
+}
+
+// Backends devolve os backends atuais, para diagnóstico.
+func (b *Balancer) Backends() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hosts := make([]string, 0, len(b.backends))
+	for _, be := range b.backends {
+		hosts = append(hosts, be.url.Host)
+	}
+	return hosts
+}
+
+func (b *Balancer) RoundTrip(req *http.Request) (*http.Response, error) {
+	be, err := b.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(req.Context())
+	span.SetAttributes(attribute.String("serviceb.backend", be.url.Host))
+
+	target := req.Clone(req.Context())
+	target.URL.Scheme = be.url.Scheme
+	target.URL.Host = be.url.Host
+	target.Host = ""
+
+	resp, err := b.transport.RoundTrip(target)
+	if b.record(be, b.failed(req.Context(), resp, err)) {
+		span.AddEvent("serviceb backend ejected", trace.WithAttributes(attribute.String("serviceb.backend", be.url.Host)))
+	}
+	if err != nil {
+		b.release(be)
+		return nil, err
+	}
+	// A requisição só termina quando o corpo é lido e fechado
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { b.release(be) }}
+	return resp, nil
+}
+
+// failed indica se a resposta conta como falha do backend. Cancelamentos do
+// cliente não dizem nada sobre a saúde do backend.
+func (b *Balancer) failed(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(ctx.Err(), context.Canceled)
+	}
+	return slices.Contains(b.settings.EjectStatuses, resp.StatusCode)
+}
+
+func (b *Balancer) acquire() (*backend, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	// Se todos estiverem ejetados, todos voltam a ser candidatos, para que a
+	// falha de um health check passivo não derrube o serviço inteiro.
+	now := b.now()
+	candidates := make([]*backend, 0, len(b.backends))
+	for _, be := range b.backends {
+		if !now.Before(be.ejectedUntil) {
+			candidates = append(candidates, be)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = b.backends
+	}
+
+	start := b.cursor % len(candidates)
+	b.cursor++
+	chosen := candidates[start]
+	if b.settings.Policy == LeastOutstanding {
+		// A busca começa no mesmo ponto do round-robin para distribuir os empates
+		for i := 1; i < len(candidates); i++ {
+			be := candidates[(start+i)%len(candidates)]
+			if be.outstanding < chosen.outstanding {
+				chosen = be
+			}
+		}
+	}
+	chosen.outstanding++
+	return chosen, nil
+}
+
+// record contabiliza o resultado de uma chamada e indica se o backend acabou
+// de ser ejetado.
+func (b *Balancer) record(be *backend, failed bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		be.failures = 0
+		return false
+	}
+	be.failures++
+	if b.settings.MaxFailures <= 0 || be.failures < b.settings.MaxFailures {
+		return false
+	}
+	be.failures = 0
+	be.ejectedUntil = b.now().Add(b.settings.EjectionDuration)
+	return true
+}
+
+// release devolve o backend escolhido em acquire.
+func (b *Balancer) release(be *backend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	be.outstanding--
+}
+
+// releaseBody devolve o backend quando o corpo da resposta é fechado, para
+// que least_outstanding conte também as respostas ainda sendo lidas.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseBody) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,339 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// hostTransport responde com o status configurado para cada host e registra
+// os hosts chamados
+type hostTransport struct {
+	mu       sync.Mutex
+	statuses map[string]int
+	errs     map[string]error
+	hosts    []string
+}
+
+func (h *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hosts = append(h.hosts, req.URL.Host)
+	if err := h.errs[req.URL.Host]; err != nil {
+		return nil, err
+	}
+	status := http.StatusOK
+	if s, ok := h.statuses[req.URL.Host]; ok {
+		status = s
+	}
+	return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+}
+
+func urls(t *testing.T, raw ...string) []*url.URL {
+	var result []*url.URL
+	for _, r := range raw {
+		u, err := url.Parse(r)
+		assert.NoError(t, err)
+		result = append(result, u)
+	}
+	return result
+}
+
+func get(t *testing.T, b *Balancer) {
+	req := httptest.NewRequest("GET", "http://serviceb/01001000", nil)
+	resp, err := b.RoundTrip(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	transport := &hostTransport{}
+	b, err := New(transport, Settings{Policy: RoundRobin})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080", "http://b3:8080"))
+
+	for i := 0; i < 6; i++ {
+		get(t, b)
+	}
+
+	assert.Equal(t, []string{"b1:8080", "b2:8080", "b3:8080", "b1:8080", "b2:8080", "b3:8080"}, transport.hosts)
+}
+
+func TestBalancer_LeastOutstanding(t *testing.T) {
+	b, err := New(&hostTransport{}, Settings{Policy: LeastOutstanding})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080", "http://b3:8080"))
+
+	// b1 e b2 ficam com requisições em andamento
+	busy1, _ := b.acquire()
+	busy2, _ := b.acquire()
+	busy3, _ := b.acquire()
+	b.release(busy3)
+	assert.Equal(t, "b1:8080", busy1.url.Host)
+	assert.Equal(t, "b2:8080", busy2.url.Host)
+
+	for i := 0; i < 3; i++ {
+		be, err := b.acquire()
+		assert.NoError(t, err)
+		assert.Equal(t, "b3:8080", be.url.Host)
+		b.release(be)
+	}
+}
+
+func TestBalancer_PassiveEjection(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	transport := &hostTransport{
+		statuses: map[string]int{"b1:8080": http.StatusBadGateway},
+		errs:     map[string]error{},
+	}
+	b, err := New(transport, Settings{Policy: RoundRobin, MaxFailures: 2, EjectionDuration: 30 * time.Second, EjectStatuses: []int{http.StatusBadGateway}})
+	assert.NoError(t, err)
+	b.now = func() time.Time { return now }
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080"))
+
+	// Duas falhas seguidas de b1 o tiram de rotação
+	for i := 0; i < 4; i++ {
+		get(t, b)
+	}
+	transport.hosts = nil
+	for i := 0; i < 3; i++ {
+		get(t, b)
+	}
+	assert.Equal(t, []string{"b2:8080", "b2:8080", "b2:8080"}, transport.hosts)
+
+	// Passado o tempo de ejeção, b1 volta
+	now = now.Add(31 * time.Second)
+	transport.statuses = nil
+	transport.hosts = nil
+	for i := 0; i < 2; i++ {
+		get(t, b)
+	}
+	assert.ElementsMatch(t, []string{"b1:8080", "b2:8080"}, transport.hosts)
+}
+
+func TestBalancer_AllEjectedFallsBackToAll(t *testing.T) {
+	transport := &hostTransport{errs: map[string]error{"b1:8080": errors.New("connection refused")}}
+	b, err := New(transport, Settings{Policy: RoundRobin, MaxFailures: 1, EjectionDuration: time.Minute})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080"))
+
+	get(t, b)
+	get(t, b)
+
+	assert.Equal(t, []string{"b1:8080", "b1:8080"}, transport.hosts)
+}
+
+func TestBalancer_OnlyEjectStatusesAreFailures(t *testing.T) {
+	transport := &hostTransport{statuses: map[string]int{"b1:8080": http.StatusServiceUnavailable}}
+	b, err := New(transport, Settings{Policy: RoundRobin, MaxFailures: 1, EjectionDuration: time.Minute, EjectStatuses: []int{http.StatusBadGateway}})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080"))
+
+	// O 503 vem do serviço B (por exemplo, breaker aberto) e não tira b1 de rotação
+	for i := 0; i < 4; i++ {
+		get(t, b)
+	}
+
+	assert.Equal(t, []string{"b1:8080", "b2:8080", "b1:8080", "b2:8080"}, transport.hosts)
+	assert.True(t, b.backends[0].ejectedUntil.IsZero())
+}
+
+func TestBalancer_ReleasesOnBodyClose(t *testing.T) {
+	b, err := New(&hostTransport{}, Settings{Policy: LeastOutstanding})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080"))
+
+	// A resposta de b1 ainda está sendo lida, então as próximas vão para b2
+	resp, err := b.RoundTrip(httptest.NewRequest("GET", "http://serviceb/01001000", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, b.backends[0].outstanding)
+	for i := 0; i < 2; i++ {
+		be, err := b.acquire()
+		assert.NoError(t, err)
+		assert.Equal(t, "b2:8080", be.url.Host)
+		b.release(be)
+	}
+
+	resp.Body.Close()
+	resp.Body.Close()
+	assert.Equal(t, 0, b.backends[0].outstanding)
+}
+
+func TestBalancer_ClientCancellationIsNotAFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, err := New(&hostTransport{errs: map[string]error{"b1:8080": context.Canceled}}, Settings{Policy: RoundRobin, MaxFailures: 1, EjectionDuration: time.Minute})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080"))
+
+	req := httptest.NewRequest("GET", "http://serviceb/01001000", nil).WithContext(ctx)
+	_, err = b.RoundTrip(req)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, b.backends[0].failures)
+	assert.True(t, b.backends[0].ejectedUntil.IsZero())
+}
+
+func TestBalancer_SetBackendsKeepsState(t *testing.T) {
+	b, err := New(&hostTransport{}, Settings{Policy: RoundRobin})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "http://b1:8080", "http://b2:8080"))
+	b.backends[0].failures = 2
+
+	b.SetBackends(urls(t, "http://b1:8080", "http://b3:8080"))
+
+	assert.Equal(t, []string{"b1:8080", "b3:8080"}, b.Backends())
+	assert.Equal(t, 2, b.backends[0].failures)
+}
+
+func TestBalancer_NoBackends(t *testing.T) {
+	b, err := New(&hostTransport{}, Settings{Policy: RoundRobin})
+	assert.NoError(t, err)
+
+	_, err = b.RoundTrip(httptest.NewRequest("GET", "http://serviceb/01001000", nil))
+	assert.ErrorIs(t, err, ErrNoBackends)
+}
+
+func TestBalancer_UnknownPolicy(t *testing.T) {
+	_, err := New(nil, Settings{Policy: "random"})
+	assert.Error(t, err)
+}
+
+func TestBalancer_SpanBackendAttribute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "proxy")
+
+	b, err := New(&hostTransport{}, Settings{Policy: RoundRobin})
+	assert.NoError(t, err)
+	b.SetBackends(urls(t, "https://b1:8443"))
+
+	req := httptest.NewRequest("GET", "http://serviceb/01001000", nil).WithContext(ctx)
+	resp, err := b.RoundTrip(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	span.End()
+
+	assert.Contains(t, recorder.Ended()[0].Attributes(), attribute.String("serviceb.backend", "b1:8443"))
+}
+
+type fakeResolver struct {
+	hosts map[string][]string
+	srvs  map[string][]*net.SRV
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := f.hosts[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func (f *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	records, ok := f.srvs[name]
+	if !ok {
+		return "", nil, errors.New("no such host")
+	}
+	return name, records, nil
+}
+
+func TestDiscovery(t *testing.T) {
+	resolver := &fakeResolver{
+		hosts: map[string][]string{"appb": {"10.0.0.2", "10.0.0.1"}},
+		srvs: map[string][]*net.SRV{"_http._tcp.appb": {
+			{Target: "appb-1.local.", Port: 8080},
+			{Target: "appb-0.local.", Port: 9090},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		discover Discover
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "dns with port",
+			discover: DNS(resolver, urls(t, "http://appb:8080")[0]),
+			expected: []string{"http://10.0.0.1:8080", "http://10.0.0.2:8080"},
+		},
+		{
+			name:     "dns with default https port",
+			discover: DNS(resolver, urls(t, "https://appb")[0]),
+			expected: []string{"https://10.0.0.1:443", "https://10.0.0.2:443"},
+		},
+		{
+			name:     "srv",
+			discover: SRV(resolver, "http", "_http._tcp.appb"),
+			expected: []string{"http://appb-0.local:9090", "http://appb-1.local:8080"},
+		},
+		{
+			name:     "unknown host",
+			discover: DNS(resolver, urls(t, "http://unknown:8080")[0]),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := New(&hostTransport{}, Settings{Policy: RoundRobin})
+			assert.NoError(t, err)
+
+			err = b.Watch(context.Background(), tt.discover, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var got []string
+			for _, be := range b.backends {
+				got = append(got, be.url.String())
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestWatch_Refresh(t *testing.T) {
+	resolver := &fakeResolver{hosts: map[string][]string{"appb": {"10.0.0.1"}}}
+	var mu sync.Mutex
+	discover := func(ctx context.Context) ([]*url.URL, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return DNS(resolver, urls(t, "http://appb:8080")[0])(ctx)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b, err := New(&hostTransport{}, Settings{Policy: RoundRobin})
+	assert.NoError(t, err)
+	assert.NoError(t, b.Watch(ctx, discover, 10*time.Millisecond))
+	assert.Equal(t, []string{"10.0.0.1:8080"}, b.Backends())
+
+	mu.Lock()
+	resolver.hosts["appb"] = []string{"10.0.0.1", "10.0.0.3"}
+	mu.Unlock()
+	assert.Eventually(t, func() bool {
+		return len(b.Backends()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// Uma falha na atualização mantém a lista anterior
+	mu.Lock()
+	delete(resolver.hosts, "appb")
+	mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, []string{"10.0.0.1:8080", "10.0.0.3:8080"}, b.Backends())
+}
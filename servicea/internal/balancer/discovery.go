@@ -0,0 +1,108 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resolver é o subconjunto de net.Resolver usado na descoberta.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// Discover devolve a lista atual de backends.
+type Discover func(ctx context.Context) ([]*url.URL, error)
+
+// DNS descobre os backends pelos registros A/AAAA do host de base, mantendo
+// o esquema e a porta de base.
+func DNS(resolver Resolver, base *url.URL) Discover {
+	return func(ctx context.Context) ([]*url.URL, error) {
+		addrs, err := resolver.LookupHost(ctx, base.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		port := base.Port()
+		if port == "" {
+			port = "80"
+			if base.Scheme == "https" {
+				port = "443"
+			}
+		}
+		urls := make([]*url.URL, 0, len(addrs))
+		for _, addr := range addrs {
+			urls = append(urls, &url.URL{Scheme: base.Scheme, Host: net.JoinHostPort(addr, port)})
+		}
+		return sorted(urls), nil
+	}
+}
+
+// SRV descobre os backends pelo registro SRV name (por exemplo
+// _http._tcp.appb), usando o alvo e a porta de cada registro.
+func SRV(resolver Resolver, scheme, name string) Discover {
+	return func(ctx context.Context) ([]*url.URL, error) {
+		_, records, err := resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		urls := make([]*url.URL, 0, len(records))
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			urls = append(urls, &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(record.Port)))})
+		}
+		return sorted(urls), nil
+	}
+}
+
+// Watch carrega os backends com discover e os atualiza a cada interval até
+// ctx ser cancelado. Só a primeira descoberta precisa ter sucesso; nas
+// seguintes, uma falha mantém a lista anterior. Com interval zero, a lista não
+// é atualizada.
+func (b *Balancer) Watch(ctx context.Context, discover Discover, interval time.Duration) error {
+	urls, err := discover(ctx)
+	if err != nil {
+		return fmt.Errorf("discover serviceb backends: %w", err)
+	}
+	if len(urls) == 0 {
+		return ErrNoBackends
+	}
+	b.SetBackends(urls)
+	if interval <= 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				urls, err := discover(ctx)
+				if err != nil || len(urls) == 0 {
+					log.Printf("failed to refresh serviceb backends, keeping %v: %v", b.Backends(), err)
+					continue
+				}
+				b.SetBackends(urls)
+			}
+		}
+	}()
+	return nil
+}
+
+// sorted ordena as URLs para que a ordem do round-robin não dependa da ordem
+// das respostas do DNS.
+func sorted(urls []*url.URL) []*url.URL {
+	sort.Slice(urls, func(i, j int) bool {
+		return urls[i].Host < urls[j].Host
+	})
+	return urls
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"io"
+	"net/http"
+)
+
+type BatchRequestBody struct {
+	ZipCodes []string `json:"zipcodes"`
+}
+
+// BatchItem é o resultado de um CEP do lote, no mesmo formato devolvido pelo
+// serviço B.
+type BatchItem struct {
+	ZipCode string          `json:"zipcode"`
+	Status  int             `json:"status"`
+	TempC   *float64        `json:"temp_c,omitempty"`
+	TempF   *float64        `json:"temp_f,omitempty"`
+	TempK   *float64        `json:"temp_k,omitempty"`
+	Error   *apierror.Error `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchItem `json:"results"`
+}
+
+// HandleBatchInput valida e remove CEPs repetidos localmente e envia os
+// válidos ao serviço B numa única requisição. CEPs inválidos voltam no
+// resultado com status 422 sem chegar ao serviço B.
+func (t *TemperatureHandler) HandleBatchInput(w http.ResponseWriter, r *http.Request) {
+	tr := otel.GetTracerProvider().Tracer("HandleZipCodeInput")
+
+	carrier := propagation.HeaderCarrier(r.Header)
+	ctx := r.Context()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	ctx, span := tr.Start(ctx, "batch zipcode validation")
+	defer span.End()
+	span.SetAttributes(telemetry.BaggageAttributes(ctx)...)
+
+	var reqBody BatchRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "invalid request body").
+			WithDetail("reason", err.Error()))
+		return
+	}
+	if len(reqBody.ZipCodes) == 0 {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "zipcodes must not be empty"))
+		return
+	}
+
+	var results []BatchItem
+	var valid []string
+	seen := make(map[string]bool)
+	for _, zipCode := range reqBody.ZipCodes {
+		cleanZip, err := zipcode.Validate(zipCode)
+		if err != nil {
+			cleanZip = zipCode
+		}
+		if seen[cleanZip] {
+			continue
+		}
+		seen[cleanZip] = true
+
+		if err != nil {
+			zipcode.RecordValidationFailure(ctx, "invalid_format")
+			results = append(results, BatchItem{
+				ZipCode: zipCode,
+				Status:  http.StatusUnprocessableEntity,
+				Error:   apierror.New(apierror.CodeInvalidZipCode, "invalid zipcode"),
+			})
+			continue
+		}
+		valid = append(valid, cleanZip)
+		results = append(results, BatchItem{ZipCode: cleanZip})
+	}
+	span.SetAttributes(
+		attribute.Int("batch.requested", len(reqBody.ZipCodes)),
+		attribute.Int("batch.valid", len(valid)),
+	)
+
+	if len(valid) > 0 {
+		payload, err := json.Marshal(BatchRequestBody{ZipCodes: valid})
+		if err != nil {
+			apierror.Write(ctx, w, http.StatusInternalServerError, apierror.New(apierror.CodeInternal, "failed to build upstream request"))
+			return
+		}
+		url := t.serviceBURL + "/batch"
+		if r.URL.RawQuery != "" {
+			url += "?" + r.URL.RawQuery
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+		if err != nil {
+			apierror.Write(ctx, w, http.StatusInternalServerError, apierror.New(apierror.CodeInternal, "failed to build upstream request"))
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		resp := t.send(ctx, w, req)
+		if resp == nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		// Erros do lote como um todo, como unidades inválidas, são repassados
+		if resp.StatusCode != http.StatusOK {
+			copyResponse(ctx, w, resp)
+			return
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			apierror.Write(ctx, w, http.StatusBadGateway, apierror.New(apierror.CodeUpstreamUnavailable, "unable to read temp body response"))
+			return
+		}
+
+		var batch BatchResponse
+		if err := json.Unmarshal(respBody, &batch); err != nil {
+			apierror.Write(ctx, w, http.StatusBadGateway, apierror.New(apierror.CodeUpstreamUnavailable, "unable to decode temp body response"))
+			return
+		}
+		byZipCode := make(map[string]BatchItem, len(batch.Results))
+		for _, item := range batch.Results {
+			byZipCode[item.ZipCode] = item
+		}
+		for i, item := range results {
+			if item.Status != 0 {
+				continue
+			}
+			if upstream, ok := byZipCode[item.ZipCode]; ok {
+				results[i] = upstream
+				continue
+			}
+			results[i].Status = http.StatusInternalServerError
+			results[i].Error = apierror.New(apierror.CodeInternal, "missing result from temperature service")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(BatchResponse{Results: results})
+}
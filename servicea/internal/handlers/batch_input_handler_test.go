@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleBatchInput(t *testing.T) {
+	var forwarded BatchRequestBody
+	var gotQuery string
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		json.NewDecoder(r.Body).Decode(&forwarded)
+		temp := 25.0
+		json.NewEncoder(w).Encode(BatchResponse{Results: []BatchItem{
+			{ZipCode: "20040020", Status: http.StatusOK, TempC: &temp},
+			{ZipCode: "01001000", Status: http.StatusNotFound, Error: apierror.New(apierror.CodeZipCodeNotFound, "can not find zipcode")},
+		}})
+	}))
+	defer serviceB.Close()
+
+	r := chi.NewRouter()
+	r.Post("/batch", New(serviceB.Client(), serviceB.URL).HandleBatchInput)
+
+	body := `{"zipcodes":["01001000","123","01001-000","20040020"]}`
+	req := httptest.NewRequest("POST", "/batch?units=c", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"01001000", "20040020"}, forwarded.ZipCodes)
+	assert.Equal(t, "units=c", gotQuery)
+
+	var got BatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Results, 3)
+	assert.Equal(t, "01001000", got.Results[0].ZipCode)
+	assert.Equal(t, http.StatusNotFound, got.Results[0].Status)
+	assert.Equal(t, "123", got.Results[1].ZipCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, got.Results[1].Status)
+	assert.Equal(t, apierror.CodeInvalidZipCode, got.Results[1].Error.Code)
+	assert.Equal(t, "20040020", got.Results[2].ZipCode)
+	assert.Equal(t, 25.0, *got.Results[2].TempC)
+}
+
+func TestHandleBatchInput_PassesThroughBatchErrors(t *testing.T) {
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"INVALID_REQUEST","message":"too many zipcodes"}`))
+	}))
+	defer serviceB.Close()
+
+	r := chi.NewRouter()
+	r.Post("/batch", New(serviceB.Client(), serviceB.URL).HandleBatchInput)
+
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(`{"zipcodes":["01001000"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code":"INVALID_REQUEST","message":"too many zipcodes"}`, w.Body.String())
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/httpapi"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// grpcRender monta a resposta JSON de um endpoint a partir da resposta gRPC
+// do serviço B.
+type grpcRender func(r *http.Request, resp *temperaturev1.GetTemperatureResponse) interface{}
+
+// address tem o mesmo formato JSON do endereço devolvido pelo serviço B em
+// ?include=address.
+type address struct {
+	ZipCode      string `json:"cep"`
+	Street       string `json:"logradouro"`
+	Complement   string `json:"complemento,omitempty"`
+	Neighborhood string `json:"bairro"`
+	City         string `json:"localidade"`
+	State        string `json:"uf"`
+	IBGE         string `json:"ibge,omitempty"`
+	DDD          string `json:"ddd,omitempty"`
+}
+
+type temperatures struct {
+	TempC *float64 `json:"temp_c,omitempty"`
+	TempF *float64 `json:"temp_f,omitempty"`
+	TempK *float64 `json:"temp_k,omitempty"`
+}
+
+type temperatureResponse struct {
+	temperatures
+	Address *address `json:"address,omitempty"`
+}
+
+type temperatureResponseV2 struct {
+	City    string `json:"city"`
+	State   string `json:"state"`
+	ZipCode string `json:"zipcode"`
+	temperatures
+	ObservedAt time.Time `json:"observed_at"`
+	Provider   string    `json:"provider"`
+}
+
+func newTemperatures(resp *temperaturev1.GetTemperatureResponse) temperatures {
+	return temperatures{TempC: resp.TempC, TempF: resp.TempF, TempK: resp.TempK}
+}
+
+func renderV1(r *http.Request, resp *temperaturev1.GetTemperatureResponse) interface{} {
+	response := temperatureResponse{temperatures: newTemperatures(resp)}
+	if httpapi.Includes(r, "address") {
+		a := resp.GetAddress()
+		response.Address = &address{
+			ZipCode:      a.GetZipcode(),
+			Street:       a.GetStreet(),
+			Complement:   a.GetComplement(),
+			Neighborhood: a.GetNeighborhood(),
+			City:         a.GetCity(),
+			State:        a.GetState(),
+			IBGE:         a.GetIbge(),
+			DDD:          a.GetDdd(),
+		}
+	}
+	return response
+}
+
+func renderV2(r *http.Request, resp *temperaturev1.GetTemperatureResponse) interface{} {
+	return temperatureResponseV2{
+		City:         resp.GetAddress().GetCity(),
+		State:        resp.GetAddress().GetState(),
+		ZipCode:      zipcode.Format(resp.GetAddress().GetZipcode()),
+		temperatures: newTemperatures(resp),
+		ObservedAt:   resp.GetObservedAt().AsTime(),
+		Provider:     resp.GetProvider(),
+	}
+}
+
+// sendGRPC consulta o serviço B via gRPC e responde no mesmo formato da API
+// HTTP, inclusive nos erros.
+func (t *TemperatureHandler) sendGRPC(ctx context.Context, w http.ResponseWriter, r *http.Request, zipCode string, render grpcRender) {
+	span := trace.SpanFromContext(ctx)
+
+	resp, err := t.grpc.GetTemperature(ctx, &temperaturev1.GetTemperatureRequest{
+		Zipcode: zipCode,
+		Units:   r.URL.Query().Get("units"),
+	})
+	if err != nil {
+		writeGRPCError(ctx, w, err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("upstream.status_code", http.StatusOK))
+	httpapi.WriteJSON(w, render(r, resp))
+}
+
+// httpStatuses traduz o código gRPC no status HTTP equivalente.
+var httpStatuses = map[codes.Code]int{
+	codes.InvalidArgument:   http.StatusBadRequest,
+	codes.NotFound:          http.StatusNotFound,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+// writeGRPCError reconstrói o envelope de erro do serviço B a partir do
+// status gRPC. Sem o ErrorInfo do serviço B, o erro é do transporte e é
+// tratado como as falhas de rede da API HTTP.
+func writeGRPCError(ctx context.Context, w http.ResponseWriter, err error) {
+	span := trace.SpanFromContext(ctx)
+	st := status.Convert(err)
+
+	var info *errdetails.ErrorInfo
+	var details map[string]interface{}
+	var retryAfter time.Duration
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *structpb.Struct:
+			details = d.AsMap()
+		case *errdetails.RetryInfo:
+			retryAfter = d.GetRetryDelay().AsDuration()
+		}
+	}
+
+	if info == nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "temperature service request failed")
+		switch st.Code() {
+		case codes.Canceled:
+			span.AddEvent("client disconnected")
+		case codes.DeadlineExceeded:
+			apierror.Write(ctx, w, http.StatusGatewayTimeout, apierror.New(apierror.CodeUpstreamTimeout, "temperature service did not respond in time"))
+		default:
+			apierror.Write(ctx, w, http.StatusBadGateway, apierror.New(apierror.CodeUpstreamUnavailable, "unable to reach temperature service"))
+		}
+		return
+	}
+
+	statusCode, ok := httpStatuses[st.Code()]
+	if !ok {
+		statusCode = http.StatusInternalServerError
+	}
+	// O serviço B responde 422 para CEPs inválidos e 400 para os demais
+	// argumentos inválidos, ambos como InvalidArgument
+	if apierror.Code(info.GetReason()) == apierror.CodeInvalidZipCode {
+		statusCode = http.StatusUnprocessableEntity
+	}
+	span.SetAttributes(attribute.Int("upstream.status_code", statusCode))
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(otelcodes.Error, "temperature service responded "+st.Code().String())
+	}
+
+	apiErr := apierror.New(apierror.Code(info.GetReason()), st.Message())
+	apiErr.Details = details
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	apierror.Write(ctx, w, statusCode, apiErr)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Mock do cliente gRPC do serviço B
+type mockTemperatureClient struct {
+	response *temperaturev1.GetTemperatureResponse
+	err      error
+	request  *temperaturev1.GetTemperatureRequest
+}
+
+func (m *mockTemperatureClient) GetTemperature(ctx context.Context, in *temperaturev1.GetTemperatureRequest, opts ...grpc.CallOption) (*temperaturev1.GetTemperatureResponse, error) {
+	m.request = in
+	return m.response, m.err
+}
+
+func grpcError(t *testing.T, code codes.Code, message string, details ...protoadapt.MessageV1) error {
+	st, err := status.New(code, message).WithDetails(details...)
+	assert.NoError(t, err)
+	return st.Err()
+}
+
+func TestHandleZipCodeInput_GRPC(t *testing.T) {
+	response := &temperaturev1.GetTemperatureResponse{
+		TempC:      proto.Float64(25),
+		TempK:      proto.Float64(298.15),
+		ObservedAt: timestamppb.New(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+		Provider:   "weatherapi",
+		Address:    &temperaturev1.Address{Zipcode: "01001-000", Street: "Praça da Sé", Neighborhood: "Sé", City: "São Paulo", State: "SP"},
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedBody string
+	}{
+		{
+			name:         "v1",
+			path:         "/?units=c,k",
+			expectedBody: `{"temp_c":25,"temp_k":298.15}`,
+		},
+		{
+			name:         "v1 with address",
+			path:         "/?units=c,k&include=address",
+			expectedBody: `{"temp_c":25,"temp_k":298.15,"address":{"cep":"01001-000","logradouro":"Praça da Sé","bairro":"Sé","localidade":"São Paulo","uf":"SP"}}`,
+		},
+		{
+			name:         "v2",
+			path:         "/v2?units=c,k",
+			expectedBody: `{"city":"São Paulo","state":"SP","zipcode":"01001-000","temp_c":25,"temp_k":298.15,"observed_at":"2026-10-18T12:00:00Z","provider":"weatherapi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockTemperatureClient{response: response}
+			router := setupRouter(New(nil, "http://serviceb.invalid").WithGRPCClient(client))
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(`{"zipcode":"01001000"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, "01001000", client.request.GetZipcode())
+			assert.Equal(t, "c,k", client.request.GetUnits())
+		})
+	}
+}
+
+func TestHandleZipCodeInput_GRPCErrors(t *testing.T) {
+	details, err := structpb.NewStruct(map[string]interface{}{"provider": "weatherapi", "retry_after_seconds": 30})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatus     int
+		expectedCode       apierror.Code
+		expectedMessage    string
+		expectedDetails    map[string]interface{}
+		expectedRetryAfter string
+	}{
+		{
+			name:            "zipcode not found",
+			err:             grpcError(t, codes.NotFound, "can not find zipcode", &errdetails.ErrorInfo{Reason: "ZIPCODE_NOT_FOUND", Domain: "serviceb"}),
+			expectedStatus:  http.StatusNotFound,
+			expectedCode:    apierror.CodeZipCodeNotFound,
+			expectedMessage: "can not find zipcode",
+		},
+		{
+			name:            "invalid zipcode",
+			err:             grpcError(t, codes.InvalidArgument, "invalid zipcode", &errdetails.ErrorInfo{Reason: "INVALID_ZIPCODE", Domain: "serviceb"}),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apierror.CodeInvalidZipCode,
+			expectedMessage: "invalid zipcode",
+		},
+		{
+			name: "rate limited",
+			err: grpcError(t, codes.ResourceExhausted, "weatherapi rate limit exceeded, please try again later",
+				&errdetails.ErrorInfo{Reason: "RATE_LIMITED", Domain: "serviceb"},
+				details,
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(30 * time.Second)}),
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedCode:       "RATE_LIMITED",
+			expectedMessage:    "weatherapi rate limit exceeded, please try again later",
+			expectedDetails:    map[string]interface{}{"provider": "weatherapi", "retry_after_seconds": 30.0},
+			expectedRetryAfter: "30",
+		},
+		{
+			name:            "connection failure",
+			err:             status.Error(codes.Unavailable, "connection refused"),
+			expectedStatus:  http.StatusBadGateway,
+			expectedCode:    apierror.CodeUpstreamUnavailable,
+			expectedMessage: "unable to reach temperature service",
+		},
+		{
+			name:            "deadline exceeded",
+			err:             status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+			expectedStatus:  http.StatusGatewayTimeout,
+			expectedCode:    apierror.CodeUpstreamTimeout,
+			expectedMessage: "temperature service did not respond in time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(New(nil, "http://serviceb.invalid").WithGRPCClient(&mockTemperatureClient{err: tt.err}))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(`{"zipcode":"01001000"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get("Retry-After"))
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expectedCode, got.Code)
+			assert.Equal(t, tt.expectedMessage, got.Message)
+			assert.Equal(t, tt.expectedDetails, got.Details)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net"
+	"net/http"
+)
+
+// hopByHopHeaders valem apenas para a conexão com o serviço B e não são
+// repassados ao cliente (RFC 9110, seção 7.6.1).
+var hopByHopHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// send executa a requisição ao serviço B e registra o status devolvido no
+// span. Falhas de rede respondem 504 quando o serviço B não respondeu a tempo
+// e 502 nos demais casos; nelas a resposta já foi escrita e o retorno é nil.
+func (t *TemperatureHandler) send(ctx context.Context, w http.ResponseWriter, req *http.Request) *http.Response {
+	span := trace.SpanFromContext(ctx)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "temperature service request failed")
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			// O cliente desistiu e não há para quem responder
+			span.AddEvent("client disconnected")
+		case isTimeout(err):
+			apierror.Write(ctx, w, http.StatusGatewayTimeout, apierror.New(apierror.CodeUpstreamTimeout, "temperature service did not respond in time"))
+		default:
+			apierror.Write(ctx, w, http.StatusBadGateway, apierror.New(apierror.CodeUpstreamUnavailable, "unable to reach temperature service"))
+		}
+		return nil
+	}
+
+	span.SetAttributes(attribute.Int("upstream.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, "temperature service responded "+resp.Status)
+	}
+	return resp
+}
+
+// copyResponse repassa ao cliente o status, os cabeçalhos e o corpo da
+// resposta do serviço B.
+func copyResponse(ctx context.Context, w http.ResponseWriter, resp *http.Response) {
+	for name, values := range resp.Header {
+		if hopByHopHeaders[name] {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Com o status já enviado, uma falha na cópia só pode ser registrada
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
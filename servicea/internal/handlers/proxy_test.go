@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProxy_PassesResponseThrough(t *testing.T) {
+	tests := []struct {
+		name            string
+		upstreamStatus  int
+		upstreamHeaders map[string]string
+		upstreamBody    string
+		expectedHeaders map[string]string
+		droppedHeaders  []string
+		expectSpanError bool
+	}{
+		{
+			name:           "not found is passed through",
+			upstreamStatus: http.StatusNotFound,
+			upstreamBody:   `{"code":"ZIPCODE_NOT_FOUND","message":"can not find zipcode"}`,
+		},
+		{
+			name:            "rate limit headers are passed through",
+			upstreamStatus:  http.StatusTooManyRequests,
+			upstreamHeaders: map[string]string{"Retry-After": "30"},
+			upstreamBody:    `{"code":"RATE_LIMITED","message":"weatherapi rate limit exceeded, please try again later"}`,
+			expectedHeaders: map[string]string{"Retry-After": "30", "Content-Type": "application/json"},
+		},
+		{
+			name:            "hop-by-hop headers are dropped",
+			upstreamStatus:  http.StatusOK,
+			upstreamHeaders: map[string]string{"Keep-Alive": "timeout=5", "Upgrade": "h2c"},
+			upstreamBody:    `{"temp_c":20.2,"temp_f":68.36,"temp_k":293.35}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+			droppedHeaders:  []string{"Keep-Alive", "Upgrade"},
+		},
+		{
+			name:            "server errors are passed through and mark the span",
+			upstreamStatus:  http.StatusServiceUnavailable,
+			upstreamBody:    `{"code":"UPSTREAM_UNAVAILABLE","message":"weather provider unavailable"}`,
+			expectSpanError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				for name, value := range tt.upstreamHeaders {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.upstreamStatus)
+				w.Write([]byte(tt.upstreamBody))
+			}))
+			defer serviceB.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			ctx, span := tp.Tracer("test").Start(context.Background(), "zipcode input")
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, serviceB.URL+"/01001000", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			resp := New(serviceB.Client(), serviceB.URL).send(ctx, w, req)
+			assert.NotNil(t, resp)
+			copyResponse(ctx, w, resp)
+			resp.Body.Close()
+			span.End()
+
+			assert.Equal(t, tt.upstreamStatus, w.Code)
+			assert.JSONEq(t, tt.upstreamBody, w.Body.String())
+			for name, value := range tt.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(name))
+			}
+			for _, name := range tt.droppedHeaders {
+				assert.Empty(t, w.Header().Get(name))
+			}
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			assert.Contains(t, spans[0].Attributes(), attribute.Int("upstream.status_code", tt.upstreamStatus))
+			assert.Equal(t, tt.expectSpanError, spans[0].Status().Code == codes.Error)
+		})
+	}
+}
+
+func TestProxy_UpstreamFailures(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		timeout        time.Duration
+		closed         bool
+		canceled       bool
+		expectedStatus int
+		expectedCode   apierror.Code
+	}{
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+			},
+			timeout:        20 * time.Millisecond,
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedCode:   apierror.CodeUpstreamTimeout,
+		},
+		{
+			name:           "connection refused",
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			closed:         true,
+			expectedStatus: http.StatusBadGateway,
+			expectedCode:   apierror.CodeUpstreamUnavailable,
+		},
+		{
+			name:     "client disconnected",
+			handler:  func(w http.ResponseWriter, r *http.Request) {},
+			canceled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceB := httptest.NewServer(tt.handler)
+			defer serviceB.Close()
+			client := serviceB.Client()
+			client.Timeout = tt.timeout
+			if tt.closed {
+				serviceB.Close()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.canceled {
+				cancel()
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, serviceB.URL+"/01001000", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			resp := New(client, serviceB.URL).send(ctx, w, req)
+			assert.Nil(t, resp)
+
+			if tt.canceled {
+				assert.Empty(t, w.Body.String())
+				return
+			}
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expectedCode, got.Code)
+		})
+	}
+}
@@ -2,44 +2,65 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
-	"io"
 	"net/http"
+	"strings"
 )
 
 type RequestBody struct {
 	ZipCode string `json:"zipcode"`
 }
 
-type ResponseServiceB struct {
-	TempC float64 `json:"temp_c"`
-	TempF float64 `json:"temp_f"`
-	TempK float64 `json:"temp_k"`
-}
-
 type TemperatureHandler struct {
+	client      *http.Client
+	serviceBURL string
+	grpc        temperaturev1.TemperatureServiceClient
 }
 
-func New() *TemperatureHandler {
-	return &TemperatureHandler{}
-}
-
-func (t *TemperatureHandler) validateZipCode(zipCode string) (string, error) {
-	cleanZip := ""
-	for _, char := range zipCode {
-		if char >= '0' && char <= '9' {
-			cleanZip += string(char)
-		}
+// New cria o handler que consulta o serviço B em serviceBURL usando client.
+// Sem client, é usado http.DefaultClient.
+func New(client *http.Client, serviceBURL string) *TemperatureHandler {
+	if client == nil {
+		client = http.DefaultClient
 	}
-	if len(cleanZip) != 8 {
-		return "", fmt.Errorf("invalid zipcode: must contain exactly 8 digits")
+	return &TemperatureHandler{
+		client:      client,
+		serviceBURL: strings.TrimRight(serviceBURL, "/"),
 	}
-	return cleanZip, nil
+}
+
+// WithGRPCClient faz as consultas de temperatura usarem o serviço gRPC do
+// serviço B. Os demais endpoints continuam usando HTTP.
+func (t *TemperatureHandler) WithGRPCClient(client temperaturev1.TemperatureServiceClient) *TemperatureHandler {
+	t.grpc = client
+	return t
 }
 
 func (t *TemperatureHandler) HandleZipCodeInput(w http.ResponseWriter, r *http.Request) {
+	t.forward(w, r, "/", "", renderV1)
+}
+
+// HandleZipCodeInputV2 consulta a versão 2 do serviço B, que inclui a
+// localização resolvida, o horário da medição e o provedor de clima.
+func (t *TemperatureHandler) HandleZipCodeInputV2(w http.ResponseWriter, r *http.Request) {
+	t.forward(w, r, "/v2/", "", renderV2)
+}
+
+// HandleForecastInput consulta a previsão diária do serviço B. Os parâmetros
+// da query, como days e units, são repassados sem alteração.
+func (t *TemperatureHandler) HandleForecastInput(w http.ResponseWriter, r *http.Request) {
+	t.forward(w, r, "/", "/forecast", nil)
+}
+
+// forward valida o CEP do corpo da requisição e consulta o serviço B em
+// prefix+CEP+suffix, repassando a resposta. Com o cliente gRPC configurado,
+// endpoints com render são consultados via gRPC.
+func (t *TemperatureHandler) forward(w http.ResponseWriter, r *http.Request, prefix, suffix string, render grpcRender) {
 	tr := otel.GetTracerProvider().Tracer("HandleZipCodeInput")
 
 	carrier := propagation.HeaderCarrier(r.Header)
@@ -47,39 +68,44 @@ func (t *TemperatureHandler) HandleZipCodeInput(w http.ResponseWriter, r *http.R
 	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
 	ctx, span := tr.Start(ctx, "zipcode validation")
 	defer span.End()
+	span.SetAttributes(telemetry.BaggageAttributes(ctx)...)
 
 	var reqBody RequestBody
 	err := json.NewDecoder(r.Body).Decode(&reqBody)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "invalid request body").
+			WithDetail("reason", err.Error()))
 		return
 	}
 
-	cleanZip, err := t.validateZipCode(reqBody.ZipCode)
+	cleanZip, err := zipcode.Validate(reqBody.ZipCode)
 	if err != nil {
-		http.Error(w, "invalid zipcode", http.StatusUnprocessableEntity)
+		zipcode.RecordValidationFailure(ctx, "invalid_format")
+		apierror.Write(ctx, w, http.StatusUnprocessableEntity, apierror.New(apierror.CodeInvalidZipCode, "invalid zipcode"))
 		return
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "http://appb:8080/"+cleanZip, nil)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if t.grpc != nil && render != nil {
+		t.sendGRPC(ctx, w, r, cleanZip, render)
+		return
 	}
 
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-	respTemp, err := http.DefaultClient.Do(req)
+	url := t.serviceBURL + prefix + cleanZip + suffix
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		http.Error(w, "can not find zipcode", http.StatusNotFound)
+		apierror.Write(ctx, w, http.StatusInternalServerError, apierror.New(apierror.CodeInternal, "failed to build upstream request"))
 		return
 	}
 
-	respBody, err := io.ReadAll(respTemp.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("unable to read temp body response: %s", err), http.StatusInternalServerError)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp := t.send(ctx, w, req)
+	if resp == nil {
 		return
 	}
+	defer resp.Body.Close()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(respBody)
+	copyResponse(ctx, w, resp)
 }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupRouter(handler *TemperatureHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Post("/", handler.HandleZipCodeInput)
+	r.Post("/v2", handler.HandleZipCodeInputV2)
+	r.Post("/forecast", handler.HandleForecastInput)
+	return r
+}
+
+func TestHandleZipCodeInput(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		body             string
+		upstreamStatus   int
+		upstreamHeaders  map[string]string
+		upstreamBody     string
+		expectedPath     string
+		expectedStatus   int
+		expectedHeaders  map[string]string
+		expectedBody     string
+		expectedCode     apierror.Code
+		expectedUpstream bool
+	}{
+		{
+			name:             "success",
+			path:             "/",
+			body:             `{"zipcode":"01001-000"}`,
+			upstreamStatus:   http.StatusOK,
+			upstreamBody:     `{"temp_c":25,"temp_f":77,"temp_k":298.15}`,
+			expectedPath:     "/01001000",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"temp_c":25,"temp_f":77,"temp_k":298.15}`,
+			expectedUpstream: true,
+		},
+		{
+			name:             "v2 with query",
+			path:             "/v2?units=c",
+			body:             `{"zipcode":"01001000"}`,
+			upstreamStatus:   http.StatusOK,
+			upstreamBody:     `{"city":"São Paulo","temp_c":25}`,
+			expectedPath:     "/v2/01001000?units=c",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"city":"São Paulo","temp_c":25}`,
+			expectedUpstream: true,
+		},
+		{
+			name:             "forecast",
+			path:             "/forecast?days=2",
+			body:             `{"zipcode":"01001000"}`,
+			upstreamStatus:   http.StatusOK,
+			upstreamBody:     `{"days":[]}`,
+			expectedPath:     "/01001000/forecast?days=2",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"days":[]}`,
+			expectedUpstream: true,
+		},
+		{
+			name:           "invalid zipcode",
+			path:           "/",
+			body:           `{"zipcode":"123"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apierror.CodeInvalidZipCode,
+		},
+		{
+			name:           "invalid body",
+			path:           "/",
+			body:           `{"zipcode":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apierror.CodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			called := false
+			serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				gotPath = r.URL.RequestURI()
+				w.Header().Set("Content-Type", "application/json")
+				for name, value := range tt.upstreamHeaders {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.upstreamStatus)
+				w.Write([]byte(tt.upstreamBody))
+			}))
+			defer serviceB.Close()
+
+			router := setupRouter(New(serviceB.Client(), serviceB.URL+"/"))
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedUpstream, called)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if !tt.expectedUpstream {
+				var got apierror.Error
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, tt.expectedCode, got.Code)
+				return
+			}
+			assert.Equal(t, tt.expectedPath, gotPath)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			for name, value := range tt.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(name))
+			}
+		})
+	}
+}
@@ -2,62 +2,219 @@ package main
 
 import (
 	"context"
+	"fmt"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/server"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/configs"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/cache"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/handlers"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/metrics"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
 	"github.com/go-chi/chi/v5"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/zipkin"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	shutdown, err := initTracer()
+	config := configs.NewConfig()
+
+	shutdown, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv("serviceb"))
 	if err != nil {
 		log.Fatal("Init Provider error: ", err)
 	}
-	defer func() {
-		if err := shutdown(ctx); err != nil {
-			log.Fatal("failed to shutdown TracerProvider: %w", err)
+
+	httpClient := utils.NewRetryingHTTPClient(nil, config.RetryPolicy())
+	store, err := newCacheStore(config)
+	if err != nil {
+		log.Fatal("Init cache error: ", err)
+	}
+
+	var breakers []*breaker.Breaker
+	var providers []viacep.Provider
+	for _, name := range config.CEPProviders {
+		provider, err := viacep.NewProvider(name, httpClient, config.ViaCEPTimeout)
+		if err != nil {
+			log.Fatal("Init cep provider error: ", err)
 		}
-	}()
+		providerBreaker := breaker.New(config.BreakerSettings(name))
+		provider.Service = viacep.NewBreakerViaCEPService(provider.Service, providerBreaker)
+		providers = append(providers, provider)
+		breakers = append(breakers, providerBreaker)
+	}
+	weatherBreaker := breaker.New(config.BreakerSettings(config.WeatherProvider))
+	breakers = append(breakers, weatherBreaker)
+	// A previsão usa sempre a WeatherAPI e, quando ela não é o provedor de
+	// clima configurado, ganha um circuit breaker próprio.
+	forecastBreaker := weatherBreaker
+	if config.WeatherAPIKey != "" && config.WeatherProvider != "weatherapi" {
+		forecastBreaker = breaker.New(config.BreakerSettings("weatherapi"))
+		breakers = append(breakers, forecastBreaker)
+	}
+	if err := metrics.RegisterBreakers(breakers...); err != nil {
+		log.Printf("failed to register circuit breaker metrics: %v", err)
+	}
 
-	config := configs.NewConfig()
-	weather := weatherapi.NewWeatherAPI(config.WeatherAPIKey)
-	temperatureHandler := handlers.New(weather)
+	var viaCEP viacep.ViaCEPInterface
+	viaCEP, err = viacep.NewProviderChain(viacep.Strategy(config.CEPStrategy), config.CEPHedgeDelay, providers...)
+	if err != nil {
+		log.Fatal("Init cep provider chain error: ", err)
+	}
+	if store != nil {
+		viaCEP = viacep.NewCachedViaCEPService(viaCEP, store, config.ViaCEPCacheTTL, config.ViaCEPNegativeTTL)
+	}
+	weather, err := weatherapi.NewProvider(config.WeatherProvider, weatherapi.ProviderConfig{
+		WeatherAPIKey:        config.WeatherAPIKey,
+		OpenWeatherMapAPIKey: config.OpenWeatherMapKey,
+		Client:               httpClient,
+		Timeout:              config.WeatherAPITimeout,
+	})
+	if err != nil {
+		log.Fatal("Init weather provider error: ", err)
+	}
+	weather = weatherapi.NewBreakerWeatherAPI(weather, weatherBreaker)
+	// O agrupamento das consultas simultâneas vale mesmo sem cache
+	weather = weatherapi.NewCachedWeatherAPI(weather, store, config.WeatherCacheTTL)
+	temperatureHandler := handlers.New(viaCEP, weather)
+	batchHandler := handlers.NewBatchHandler(temperatureHandler, config.BatchMaxSize, config.BatchConcurrency)
+	healthHandler := handlers.NewHealthHandler(breakers...)
+	var forecastHandler *handlers.ForecastHandler
+	if config.WeatherAPIKey != "" {
+		forecast := weatherapi.NewWeatherAPI(config.WeatherAPIKey, httpClient, config.WeatherAPITimeout)
+		forecastHandler = handlers.NewForecastHandler(temperatureHandler, weatherapi.NewBreakerForecast(forecast, forecastBreaker))
+	} else {
+		log.Print("WEATHER_API_KEY not set, forecast endpoint disabled")
+	}
 
 	r := chi.NewRouter()
+	r.Use(telemetry.HTTPMetrics())
+	r.Handle("/metrics", telemetry.MetricsHandler())
+	r.Get("/health", healthHandler.GetHealth)
 	r.Get("/{zipCode}", temperatureHandler.GetTemperature)
-	http.ListenAndServe(":8080", r)
+	r.Get("/v2/{zipCode}", temperatureHandler.GetTemperatureV2)
+	if forecastHandler != nil {
+		r.Get("/{zipCode}/forecast", forecastHandler.GetForecast)
+	}
+	r.Post("/batch", batchHandler.GetBatchTemperatures)
+
+	httpServer := &http.Server{
+		Addr:              config.ListenAddr,
+		Handler:           r,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+	// A falha de qualquer um dos servidores encerra o processo pelo mesmo
+	// caminho do sinal
+	serverErr := make(chan error, 2)
+	server.Start(httpServer, serverErr)
+	var grpcServer *grpc.Server
+	if config.GRPCListenAddr != "" {
+		grpcServer = newGRPCServer(handlers.NewGRPCServer(temperatureHandler))
+		go func() {
+			if err := serveGRPC(config.GRPCListenAddr, grpcServer); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+	var failed bool
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		log.Print(err)
+		failed = true
+	}
+
+	// SHUTDOWN_TIMEOUT é o prazo de todo o encerramento, contado a partir do
+	// sinal: primeiro as requisições em andamento (HTTP e gRPC, em paralelo),
+	// depois a telemetria
+	log.Print("shutting down, draining in-flight requests")
+	drainCtx, flushCtx, cancelShutdown := server.ShutdownContexts(config.ShutdownTimeout)
+	defer cancelShutdown()
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer != nil {
+			stopGRPC(drainCtx, grpcServer)
+		}
+	}()
+	if err := server.Shutdown(drainCtx, httpServer); err != nil {
+		log.Printf("http server shutdown error: %v", err)
+	}
+	<-grpcStopped
+	if err := shutdown(flushCtx); err != nil {
+		log.Printf("failed to shutdown telemetry: %v", err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// newGRPCServer expõe a consulta de temperatura via gRPC. O stats handler do
+// otelgrpc extrai o contexto propagado pelo serviço A, ligando os traces.
+func newGRPCServer(server temperaturev1.TemperatureServiceServer) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	temperaturev1.RegisterTemperatureServiceServer(grpcServer, server)
+	return grpcServer
 }
 
-func initTracer() (func(ctx context.Context) error, error) {
-	traceExporter, err := zipkin.New("http://zipkin:9411/api/v2/spans")
+// serveGRPC atende as chamadas gRPC em addr até grpcServer ser encerrado.
+func serveGRPC(addr string, grpcServer *grpc.Server) error {
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName("serviceb"),
-		)),
-		sdktrace.WithSpanProcessor(bsp),
-	)
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
-
-	return tracerProvider.Shutdown, nil
+		return fmt.Errorf("grpc listener: %w", err)
+	}
+	if err := grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("grpc server: %w", err)
+	}
+	return nil
+}
+
+// stopGRPC espera as chamadas em andamento terminarem e, quando ctx expira,
+// encerra as que restarem.
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Print("grpc graceful stop timed out, closing remaining calls")
+		grpcServer.Stop()
+	}
+}
+
+func newCacheStore(config *configs.Config) (cache.Store, error) {
+	switch config.CacheBackend {
+	case "none":
+		return nil, nil
+	case "memory":
+		return cache.NewLRU(config.CacheSize), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+			DB:       config.RedisDB,
+		})
+		return cache.NewRedis(client, "serviceb:"), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", config.CacheBackend)
+	}
 }
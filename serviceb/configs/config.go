@@ -2,13 +2,51 @@ package configs
 
 import (
 	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
 	"github.com/spf13/viper"
+	"strings"
+	"time"
 )
 
 var config *Config
 
 type Config struct {
-	WeatherAPIKey string `mapstructure:"WEATHER_API_KEY"`
+	ListenAddr        string        `mapstructure:"HTTP_LISTEN_ADDR"`
+	ReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	WeatherProvider   string        `mapstructure:"WEATHER_PROVIDER"`
+	WeatherAPIKey     string        `mapstructure:"WEATHER_API_KEY"`
+	OpenWeatherMapKey string        `mapstructure:"OPENWEATHERMAP_API_KEY"`
+	ViaCEPTimeout     time.Duration `mapstructure:"VIACEP_TIMEOUT"`
+	WeatherAPITimeout time.Duration `mapstructure:"WEATHER_API_TIMEOUT"`
+	CEPProviders      []string      `mapstructure:"CEP_PROVIDERS"`
+	CEPStrategy       string        `mapstructure:"CEP_STRATEGY"`
+	CEPHedgeDelay     time.Duration `mapstructure:"CEP_HEDGE_DELAY"`
+	RetryMaxAttempts  int           `mapstructure:"RETRY_MAX_ATTEMPTS"`
+	RetryInitialDelay time.Duration `mapstructure:"RETRY_INITIAL_BACKOFF"`
+	RetryMaxDelay     time.Duration `mapstructure:"RETRY_MAX_BACKOFF"`
+	RetryMultiplier   float64       `mapstructure:"RETRY_MULTIPLIER"`
+	RetryJitter       float64       `mapstructure:"RETRY_JITTER"`
+	BreakerWindowSize int           `mapstructure:"BREAKER_WINDOW_SIZE"`
+	BreakerMinCalls   int           `mapstructure:"BREAKER_MIN_REQUESTS"`
+	BreakerFailRate   float64       `mapstructure:"BREAKER_FAILURE_RATE"`
+	BreakerCoolDown   time.Duration `mapstructure:"BREAKER_OPEN_TIMEOUT"`
+	BreakerHalfOpen   int           `mapstructure:"BREAKER_HALF_OPEN_REQUESTS"`
+	CacheBackend      string        `mapstructure:"CACHE_BACKEND"`
+	CacheSize         int           `mapstructure:"CACHE_SIZE"`
+	RedisAddr         string        `mapstructure:"REDIS_ADDR"`
+	RedisPassword     string        `mapstructure:"REDIS_PASSWORD"`
+	RedisDB           int           `mapstructure:"REDIS_DB"`
+	ViaCEPCacheTTL    time.Duration `mapstructure:"VIACEP_CACHE_TTL"`
+	ViaCEPNegativeTTL time.Duration `mapstructure:"VIACEP_NEGATIVE_CACHE_TTL"`
+	WeatherCacheTTL   time.Duration `mapstructure:"WEATHER_CACHE_TTL"`
+	BatchMaxSize      int           `mapstructure:"BATCH_MAX_SIZE"`
+	BatchConcurrency  int           `mapstructure:"BATCH_CONCURRENCY"`
+	GRPCListenAddr    string        `mapstructure:"GRPC_LISTEN_ADDR"`
 }
 
 func NewConfig() *Config {
@@ -37,7 +75,41 @@ func loadConfig() (*Config, error) {
 	}
 
 	// Define valores padrão
+	viper.SetDefault("HTTP_LISTEN_ADDR", ":8080")
+	viper.SetDefault("HTTP_READ_TIMEOUT", 10*time.Second)
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", 5*time.Second)
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", 120*time.Second)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("WEATHER_PROVIDER", "weatherapi")
 	viper.SetDefault("WEATHER_API_KEY", "")
+	viper.SetDefault("OPENWEATHERMAP_API_KEY", "")
+	viper.SetDefault("VIACEP_TIMEOUT", 3*time.Second)
+	viper.SetDefault("WEATHER_API_TIMEOUT", 5*time.Second)
+	viper.SetDefault("CEP_PROVIDERS", "viacep,brasilapi,opencep")
+	viper.SetDefault("CEP_STRATEGY", "sequential")
+	viper.SetDefault("CEP_HEDGE_DELAY", 300*time.Millisecond)
+	viper.SetDefault("RETRY_MAX_ATTEMPTS", 3)
+	viper.SetDefault("RETRY_INITIAL_BACKOFF", 100*time.Millisecond)
+	viper.SetDefault("RETRY_MAX_BACKOFF", 2*time.Second)
+	viper.SetDefault("RETRY_MULTIPLIER", 2.0)
+	viper.SetDefault("RETRY_JITTER", 0.2)
+	viper.SetDefault("BREAKER_WINDOW_SIZE", 20)
+	viper.SetDefault("BREAKER_MIN_REQUESTS", 10)
+	viper.SetDefault("BREAKER_FAILURE_RATE", 0.5)
+	viper.SetDefault("BREAKER_OPEN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("BREAKER_HALF_OPEN_REQUESTS", 3)
+	viper.SetDefault("CACHE_BACKEND", "memory")
+	viper.SetDefault("CACHE_SIZE", 10000)
+	viper.SetDefault("REDIS_ADDR", "localhost:6379")
+	viper.SetDefault("REDIS_PASSWORD", "")
+	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("VIACEP_CACHE_TTL", 24*time.Hour)
+	viper.SetDefault("VIACEP_NEGATIVE_CACHE_TTL", 10*time.Minute)
+	viper.SetDefault("WEATHER_CACHE_TTL", 5*time.Minute)
+	viper.SetDefault("BATCH_MAX_SIZE", 100)
+	viper.SetDefault("BATCH_CONCURRENCY", 8)
+	viper.SetDefault("GRPC_LISTEN_ADDR", ":50051")
 
 	// Cria uma nova instância de Config
 	config := &Config{}
@@ -47,12 +119,75 @@ func loadConfig() (*Config, error) {
 	if weatherAPIKey != "" {
 		config.WeatherAPIKey = weatherAPIKey
 	}
+	config.ListenAddr = viper.GetString("HTTP_LISTEN_ADDR")
+	config.ReadTimeout = viper.GetDuration("HTTP_READ_TIMEOUT")
+	config.ReadHeaderTimeout = viper.GetDuration("HTTP_READ_HEADER_TIMEOUT")
+	config.WriteTimeout = viper.GetDuration("HTTP_WRITE_TIMEOUT")
+	config.IdleTimeout = viper.GetDuration("HTTP_IDLE_TIMEOUT")
+	config.ShutdownTimeout = viper.GetDuration("SHUTDOWN_TIMEOUT")
+	config.WeatherProvider = viper.GetString("WEATHER_PROVIDER")
+	config.OpenWeatherMapKey = viper.GetString("OPENWEATHERMAP_API_KEY")
+	config.ViaCEPTimeout = viper.GetDuration("VIACEP_TIMEOUT")
+	config.WeatherAPITimeout = viper.GetDuration("WEATHER_API_TIMEOUT")
+	for _, provider := range strings.Split(viper.GetString("CEP_PROVIDERS"), ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			config.CEPProviders = append(config.CEPProviders, provider)
+		}
+	}
+	config.CEPStrategy = viper.GetString("CEP_STRATEGY")
+	config.CEPHedgeDelay = viper.GetDuration("CEP_HEDGE_DELAY")
+	config.RetryMaxAttempts = viper.GetInt("RETRY_MAX_ATTEMPTS")
+	config.RetryInitialDelay = viper.GetDuration("RETRY_INITIAL_BACKOFF")
+	config.RetryMaxDelay = viper.GetDuration("RETRY_MAX_BACKOFF")
+	config.RetryMultiplier = viper.GetFloat64("RETRY_MULTIPLIER")
+	config.RetryJitter = viper.GetFloat64("RETRY_JITTER")
+	config.BreakerWindowSize = viper.GetInt("BREAKER_WINDOW_SIZE")
+	config.BreakerMinCalls = viper.GetInt("BREAKER_MIN_REQUESTS")
+	config.BreakerFailRate = viper.GetFloat64("BREAKER_FAILURE_RATE")
+	config.BreakerCoolDown = viper.GetDuration("BREAKER_OPEN_TIMEOUT")
+	config.BreakerHalfOpen = viper.GetInt("BREAKER_HALF_OPEN_REQUESTS")
+	config.CacheBackend = viper.GetString("CACHE_BACKEND")
+	config.CacheSize = viper.GetInt("CACHE_SIZE")
+	config.RedisAddr = viper.GetString("REDIS_ADDR")
+	config.RedisPassword = viper.GetString("REDIS_PASSWORD")
+	config.RedisDB = viper.GetInt("REDIS_DB")
+	config.ViaCEPCacheTTL = viper.GetDuration("VIACEP_CACHE_TTL")
+	config.ViaCEPNegativeTTL = viper.GetDuration("VIACEP_NEGATIVE_CACHE_TTL")
+	config.WeatherCacheTTL = viper.GetDuration("WEATHER_CACHE_TTL")
+	config.BatchMaxSize = viper.GetInt("BATCH_MAX_SIZE")
+	config.BatchConcurrency = viper.GetInt("BATCH_CONCURRENCY")
+	config.GRPCListenAddr = viper.GetString("GRPC_LISTEN_ADDR")
 
 	// Validação das configurações obrigatórias
-	if config.WeatherAPIKey == "" {
+	if config.WeatherProvider == "weatherapi" && config.WeatherAPIKey == "" {
 		return nil, fmt.Errorf("WEATHER_API_KEY é obrigatória")
 	}
+	if config.WeatherProvider == "openweathermap" && config.OpenWeatherMapKey == "" {
+		return nil, fmt.Errorf("OPENWEATHERMAP_API_KEY é obrigatória")
+	}
 
 	return config, nil
 
 }
+
+func (c *Config) RetryPolicy() utils.RetryPolicy {
+	return utils.RetryPolicy{
+		MaxAttempts:    c.RetryMaxAttempts,
+		InitialBackoff: c.RetryInitialDelay,
+		MaxBackoff:     c.RetryMaxDelay,
+		Multiplier:     c.RetryMultiplier,
+		Jitter:         c.RetryJitter,
+	}
+}
+
+func (c *Config) BreakerSettings(name string) breaker.Settings {
+	return breaker.Settings{
+		Name:                 name,
+		WindowSize:           c.BreakerWindowSize,
+		MinRequests:          c.BreakerMinCalls,
+		FailureRateThreshold: c.BreakerFailRate,
+		OpenTimeout:          c.BreakerCoolDown,
+		HalfOpenMaxRequests:  c.BreakerHalfOpen,
+		IsFailure:            utils.IsUpstreamFailure,
+	}
+}
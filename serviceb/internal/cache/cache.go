@@ -0,0 +1,13 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store é o backend de cache usado pelos decorators de viacep e weatherapi.
+// Get devolve found=false quando a chave não existe ou expirou.
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	assert.NoError(t, c.Set(ctx, "01001000", []byte("São Paulo"), time.Minute))
+	assert.NoError(t, c.Set(ctx, "20040002", []byte("Rio de Janeiro"), time.Minute))
+
+	value, found, err := c.Get(ctx, "01001000")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("São Paulo"), value)
+
+	// "20040002" é o menos usado e deve ser descartado
+	assert.NoError(t, c.Set(ctx, "30130010", []byte("Belo Horizonte"), time.Minute))
+	_, found, _ = c.Get(ctx, "20040002")
+	assert.False(t, found)
+	assert.Equal(t, 2, c.Len())
+
+	now = now.Add(time.Minute)
+	_, found, _ = c.Get(ctx, "01001000")
+	assert.False(t, found)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLRU_Overwrite(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	assert.NoError(t, c.Set(ctx, "key", []byte("old"), 0))
+	assert.NoError(t, c.Set(ctx, "key", []byte("new"), 0))
+
+	value, found, err := c.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("new"), value)
+	assert.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/httpapi"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/units"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type BatchRequest struct {
+	ZipCodes []string `json:"zipcodes"`
+}
+
+// BatchItem é o resultado de um CEP do lote: as temperaturas em caso de
+// sucesso ou o mesmo envelope de erro da consulta individual.
+type BatchItem struct {
+	ZipCode string `json:"zipcode"`
+	Status  int    `json:"status"`
+	Temperatures
+	Error *apierror.Error `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchItem `json:"results"`
+}
+
+type BatchHandler struct {
+	temperature *TemperatureHandler
+	maxSize     int
+	concurrency int
+}
+
+func NewBatchHandler(temperature *TemperatureHandler, maxSize, concurrency int) *BatchHandler {
+	return &BatchHandler{
+		temperature: temperature,
+		maxSize:     maxSize,
+		concurrency: concurrency,
+	}
+}
+
+// GetBatchTemperatures consulta vários CEPs de uma vez. CEPs repetidos são
+// consultados uma única vez, assim como o clima de cada cidade, e cada CEP
+// ganha um span filho próprio.
+func (b *BatchHandler) GetBatchTemperatures(w http.ResponseWriter, r *http.Request) {
+	ctx, span := startSpan(r, "batch temperature")
+	defer span.End()
+
+	scales, ok := parseUnits(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "invalid request body").
+			WithDetail("reason", err.Error()))
+		return
+	}
+	zipCodes := b.uniqueZipCodes(req.ZipCodes)
+	if len(zipCodes) == 0 {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "zipcodes must not be empty"))
+		return
+	}
+	if b.maxSize > 0 && len(zipCodes) > b.maxSize {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, "too many zipcodes").
+			WithDetail("max_size", b.maxSize))
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("batch.requested", len(req.ZipCodes)),
+		attribute.Int("batch.unique", len(zipCodes)),
+	)
+
+	weather := newWeatherMemo(b.temperature.weatherAPI)
+	results := make([]BatchItem, len(zipCodes))
+
+	var group errgroup.Group
+	if b.concurrency > 0 {
+		group.SetLimit(b.concurrency)
+	}
+	for i, zipCode := range zipCodes {
+		group.Go(func() error {
+			results[i] = b.resolveItem(ctx, zipCode, weather, scales)
+			return nil
+		})
+	}
+	group.Wait()
+
+	if clientGone(ctx) {
+		return
+	}
+	httpapi.WriteJSON(w, BatchResponse{Results: results})
+}
+
+func (b *BatchHandler) resolveItem(ctx context.Context, zipCode string, weather *weatherMemo, scales []units.Scale) BatchItem {
+	ctx, span := otel.GetTracerProvider().Tracer("GetBatchTemperatures").Start(ctx, "batch item",
+		trace.WithAttributes(attribute.String("zipcode", zipCode)))
+	defer span.End()
+
+	_, weatherResponse, failure := b.temperature.resolve(ctx, zipCode, weather.GetTempByCity)
+	if failure != nil {
+		span.SetAttributes(attribute.Int("batch.item.status", failure.status))
+		return BatchItem{ZipCode: zipCode, Status: failure.status, Error: failure.apiErr}
+	}
+
+	span.SetAttributes(attribute.Int("batch.item.status", http.StatusOK))
+	return BatchItem{
+		ZipCode:      zipCode,
+		Status:       http.StatusOK,
+		Temperatures: newTemperatures(weatherResponse.Temperature, scales),
+	}
+}
+
+// uniqueZipCodes remove CEPs repetidos, comparando-os já normalizados, e
+// mantém a ordem da primeira ocorrência. CEPs inválidos são mantidos como
+// vieram, para que o erro aponte o valor enviado.
+func (b *BatchHandler) uniqueZipCodes(zipCodes []string) []string {
+	seen := make(map[string]bool, len(zipCodes))
+	var unique []string
+	for _, zipCode := range zipCodes {
+		key, err := zipcode.Validate(zipCode)
+		if err != nil {
+			key = zipCode
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, key)
+	}
+	return unique
+}
+
+// weatherMemo garante uma única consulta de clima por cidade dentro de um
+// lote; os CEPs da mesma cidade aguardam e compartilham o resultado.
+type weatherMemo struct {
+	weatherAPI weatherapi.WeatherAPIInterface
+	mu         sync.Mutex
+	calls      map[string]*weatherCall
+}
+
+type weatherCall struct {
+	done     chan struct{}
+	response weatherapi.Response
+	err      error
+}
+
+func newWeatherMemo(weatherAPI weatherapi.WeatherAPIInterface) *weatherMemo {
+	return &weatherMemo{
+		weatherAPI: weatherAPI,
+		calls:      make(map[string]*weatherCall),
+	}
+}
+
+func (m *weatherMemo) GetTempByCity(ctx context.Context, city string) (weatherapi.Response, error) {
+	key := strings.ToLower(city)
+
+	m.mu.Lock()
+	call, found := m.calls[key]
+	if !found {
+		call = &weatherCall{done: make(chan struct{})}
+		m.calls[key] = call
+	}
+	m.mu.Unlock()
+
+	if found {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("weather.deduplicated", true))
+		select {
+		case <-call.done:
+			return call.response, call.err
+		case <-ctx.Done():
+			return weatherapi.Response{}, ctx.Err()
+		}
+	}
+
+	call.response, call.err = m.weatherAPI.GetTempByCity(ctx, city)
+	close(call.done)
+	return call.response, call.err
+}
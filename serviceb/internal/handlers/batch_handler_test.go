@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Mock de ViaCEP com cidades por CEP, seguro para uso concorrente
+type cityByZipCode struct {
+	cities map[string]string
+	calls  atomic.Int32
+}
+
+func (m *cityByZipCode) GetAddressByZipCode(ctx context.Context, zipCode string) (viacep.Address, error) {
+	m.calls.Add(1)
+	city, ok := m.cities[zipCode]
+	if !ok {
+		return viacep.Address{}, viacep.ErrNotFound
+	}
+	return viacep.Address{ZipCode: zipCode, City: city}, nil
+}
+
+// Mock de WeatherAPI que conta as chamadas por cidade e a concorrência máxima
+type countingWeatherAPI struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	calls    map[string]int
+	inFlight int
+	maxSeen  int
+}
+
+func (m *countingWeatherAPI) GetTempByCity(ctx context.Context, city string) (weatherapi.Response, error) {
+	m.mu.Lock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[city]++
+	m.inFlight++
+	if m.inFlight > m.maxSeen {
+		m.maxSeen = m.inFlight
+	}
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+	return weatherapi.NewResponse(20), nil
+}
+
+func setupBatchRouter(handler *BatchHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Post("/batch", handler.GetBatchTemperatures)
+	return r
+}
+
+func TestGetBatchTemperatures(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(originalTP)
+
+	viaCEP := &cityByZipCode{cities: map[string]string{
+		"01001000": "São Paulo",
+		"01310100": "São Paulo",
+		"20040020": "Rio de Janeiro",
+	}}
+	weather := &countingWeatherAPI{}
+	router := setupBatchRouter(NewBatchHandler(New(viaCEP, weather), 10, 4))
+
+	body := `{"zipcodes":["01001000","01001-000","20040020","01310100","123","99999999"]}`
+	req := httptest.NewRequest("POST", "/batch?units=c", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var got BatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+
+	var zipCodes []string
+	statuses := map[string]int{}
+	codes := map[string]apierror.Code{}
+	for _, item := range got.Results {
+		zipCodes = append(zipCodes, item.ZipCode)
+		statuses[item.ZipCode] = item.Status
+		if item.Error != nil {
+			codes[item.ZipCode] = item.Error.Code
+		} else {
+			assert.Equal(t, 20.0, *item.TempC)
+			assert.Nil(t, item.TempK)
+		}
+	}
+	assert.Equal(t, []string{"01001000", "20040020", "01310100", "123", "99999999"}, zipCodes)
+	assert.Equal(t, map[string]int{
+		"01001000": http.StatusOK,
+		"20040020": http.StatusOK,
+		"01310100": http.StatusOK,
+		"123":      http.StatusUnprocessableEntity,
+		"99999999": http.StatusNotFound,
+	}, statuses)
+	assert.Equal(t, map[string]apierror.Code{
+		"123":      apierror.CodeInvalidZipCode,
+		"99999999": apierror.CodeZipCodeNotFound,
+	}, codes)
+
+	assert.Equal(t, int32(4), viaCEP.calls.Load())
+	assert.Equal(t, map[string]int{"São Paulo": 1, "Rio de Janeiro": 1}, weather.calls)
+
+	var parent sdktrace.ReadOnlySpan
+	var items []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "batch temperature":
+			parent = span
+		case "batch item":
+			items = append(items, span)
+		}
+	}
+	assert.NotNil(t, parent)
+	assert.Len(t, items, 5)
+	for _, item := range items {
+		assert.Equal(t, parent.SpanContext().SpanID(), item.Parent().SpanID())
+	}
+}
+
+func TestGetBatchTemperatures_BoundedConcurrency(t *testing.T) {
+	cities := map[string]string{}
+	var zipCodes []string
+	for _, zipCode := range []string{"01001000", "01001001", "01001002", "01001003", "01001004", "01001005"} {
+		cities[zipCode] = "Cidade " + zipCode
+		zipCodes = append(zipCodes, zipCode)
+	}
+	weather := &countingWeatherAPI{delay: 20 * time.Millisecond}
+	router := setupBatchRouter(NewBatchHandler(New(&cityByZipCode{cities: cities}, weather), 10, 2))
+
+	body, _ := json.Marshal(BatchRequest{ZipCodes: zipCodes})
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, weather.calls, 6)
+	assert.LessOrEqual(t, weather.maxSeen, 2)
+}
+
+func TestGetBatchTemperatures_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed body", body: `{"zipcodes":`},
+		{name: "empty list", body: `{"zipcodes":[]}`},
+		{name: "too many zipcodes", body: `{"zipcodes":["01001000","01001001","01001002"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCEP := &cityByZipCode{}
+			router := setupBatchRouter(NewBatchHandler(New(viaCEP, &countingWeatherAPI{}), 2, 2))
+
+			req := httptest.NewRequest("POST", "/batch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, apierror.CodeInvalidRequest, got.Code)
+			assert.Equal(t, int32(0), viaCEP.calls.Load())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/httpapi"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/units"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"net/http"
+	"strconv"
+)
+
+// defaultForecastDays é o horizonte usado quando ?days= não é informado e
+// coincide com o limite do plano gratuito da WeatherAPI.
+const defaultForecastDays = 3
+
+// ForecastDay traz as temperaturas de um dia apenas nas escalas pedidas em
+// ?units=.
+type ForecastDay struct {
+	Date string       `json:"date"`
+	Min  Temperatures `json:"min"`
+	Max  Temperatures `json:"max"`
+	Avg  Temperatures `json:"avg"`
+}
+
+type ForecastResponse struct {
+	City     string        `json:"city"`
+	State    string        `json:"state"`
+	ZipCode  string        `json:"zipcode"`
+	Days     []ForecastDay `json:"days"`
+	Provider string        `json:"provider"`
+}
+
+type ForecastHandler struct {
+	temperature *TemperatureHandler
+	forecast    weatherapi.ForecastInterface
+}
+
+func NewForecastHandler(temperature *TemperatureHandler, forecast weatherapi.ForecastInterface) *ForecastHandler {
+	return &ForecastHandler{
+		temperature: temperature,
+		forecast:    forecast,
+	}
+}
+
+// GetForecast devolve a previsão diária da cidade do CEP para os próximos
+// ?days= dias.
+func (f *ForecastHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	ctx, span := startSpan(r, "zipcode forecast")
+	defer span.End()
+
+	scales, ok := parseUnits(ctx, w, r)
+	if !ok {
+		return
+	}
+	days, ok := parseDays(ctx, w, r)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.Int("forecast.days", days))
+
+	address, failure := f.temperature.resolveAddress(ctx, chi.URLParam(r, "zipCode"))
+	if failure != nil {
+		if !clientGone(ctx) {
+			failure.write(ctx, w)
+		}
+		return
+	}
+
+	forecast, err := f.forecast.GetForecastByCity(ctx, address.City, days)
+	if err != nil {
+		failure = weatherFailure(ctx, err)
+		if !clientGone(ctx) {
+			failure.write(ctx, w)
+		}
+		return
+	}
+
+	httpapi.WriteJSON(w, newForecastResponse(address.City, address.State, address.ZipCode, forecast, scales))
+}
+
+func newForecastResponse(city, state, zipCode string, forecast weatherapi.Forecast, scales []units.Scale) ForecastResponse {
+	response := ForecastResponse{
+		City:     city,
+		State:    state,
+		ZipCode:  zipcode.Format(zipCode),
+		Days:     make([]ForecastDay, 0, len(forecast.Days)),
+		Provider: forecast.Provider,
+	}
+	for _, day := range forecast.Days {
+		response.Days = append(response.Days, ForecastDay{
+			Date: day.Date,
+			Min:  newTemperatures(day.Min, scales),
+			Max:  newTemperatures(day.Max, scales),
+			Avg:  newTemperatures(day.Avg, scales),
+		})
+	}
+	return response
+}
+
+// parseDays lê o horizonte da previsão em ?days=, respondendo 400 quando ele
+// não é um número entre 1 e weatherapi.MaxForecastDays.
+func parseDays(ctx context.Context, w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("days")
+	if value == "" {
+		return defaultForecastDays, true
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > weatherapi.MaxForecastDays {
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest,
+			fmt.Sprintf("days must be a number between 1 and %d", weatherapi.MaxForecastDays)).
+			WithDetail("parameter", "days"))
+		return 0, false
+	}
+	return days, true
+}
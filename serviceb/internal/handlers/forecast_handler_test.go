@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Mock da previsão que guarda os dias pedidos
+type mockForecast struct {
+	mockResponse weatherapi.Forecast
+	mockError    error
+	days         int
+}
+
+func (m *mockForecast) GetForecastByCity(ctx context.Context, city string, days int) (weatherapi.Forecast, error) {
+	m.days = days
+	return m.mockResponse, m.mockError
+}
+
+func TestGetForecast(t *testing.T) {
+	forecast := weatherapi.Forecast{
+		Provider: "weatherapi",
+		Days: []weatherapi.ForecastDay{{
+			Date: "2026-10-18",
+			Min:  weatherapi.NewResponse(15).Temperature,
+			Max:  weatherapi.NewResponse(25).Temperature,
+			Avg:  weatherapi.NewResponse(20.2).Temperature,
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		viaCEPErr      error
+		forecastErr    error
+		expectedStatus int
+		expectedDays   int
+		expected       string
+		expectedCode   apierror.Code
+	}{
+		{
+			name:           "default days and all scales",
+			path:           "/01001000/forecast",
+			expectedStatus: http.StatusOK,
+			expectedDays:   3,
+			expected: `{"city":"São Paulo","state":"SP","zipcode":"01001-000","provider":"weatherapi","days":[{
+				"date":"2026-10-18",
+				"min":{"temp_c":15,"temp_f":59,"temp_k":288.15},
+				"max":{"temp_c":25,"temp_f":77,"temp_k":298.15},
+				"avg":{"temp_c":20.2,"temp_f":68.36,"temp_k":293.35}
+			}]}`,
+		},
+		{
+			name:           "days and units",
+			path:           "/01001000/forecast?days=7&units=k",
+			expectedStatus: http.StatusOK,
+			expectedDays:   7,
+			expected: `{"city":"São Paulo","state":"SP","zipcode":"01001-000","provider":"weatherapi","days":[{
+				"date":"2026-10-18",
+				"min":{"temp_k":288.15},
+				"max":{"temp_k":298.15},
+				"avg":{"temp_k":293.35}
+			}]}`,
+		},
+		{
+			name:           "days is not a number",
+			path:           "/01001000/forecast?days=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apierror.CodeInvalidRequest,
+		},
+		{
+			name:           "days above the limit",
+			path:           "/01001000/forecast?days=15",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apierror.CodeInvalidRequest,
+		},
+		{
+			name:           "invalid zipcode",
+			path:           "/123/forecast",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apierror.CodeInvalidZipCode,
+		},
+		{
+			name:           "zipcode not found",
+			path:           "/99999999/forecast",
+			viaCEPErr:      viacep.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   apierror.CodeZipCodeNotFound,
+		},
+		{
+			name:           "forecast rate limited",
+			path:           "/01001000/forecast",
+			forecastErr:    &utils.LookupError{Provider: "weatherapi", Err: &utils.UpstreamError{StatusCode: http.StatusTooManyRequests}},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   apierror.CodeRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecastAPI := &mockForecast{mockResponse: forecast, mockError: tt.forecastErr}
+			temperature := New(&mockViaCEPService{mockResponse: "São Paulo", mockError: tt.viaCEPErr}, &mockWeatherAPI{})
+			r := chi.NewRouter()
+			r.Get("/{zipCode}/forecast", NewForecastHandler(temperature, forecastAPI).GetForecast)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedDays, forecastAPI.days)
+				assert.JSONEq(t, tt.expected, w.Body.String())
+				return
+			}
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expectedCode, got.Code)
+		})
+	}
+}
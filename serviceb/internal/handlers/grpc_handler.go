@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/units"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/http"
+)
+
+// GRPCServer expõe a consulta de temperatura por CEP via gRPC, com as mesmas
+// regras e erros da API HTTP.
+type GRPCServer struct {
+	temperaturev1.UnimplementedTemperatureServiceServer
+	temperature *TemperatureHandler
+}
+
+func NewGRPCServer(temperature *TemperatureHandler) *GRPCServer {
+	return &GRPCServer{temperature: temperature}
+}
+
+func (s *GRPCServer) GetTemperature(ctx context.Context, req *temperaturev1.GetTemperatureRequest) (*temperaturev1.GetTemperatureResponse, error) {
+	// O span do servidor gRPC, com o contexto propagado pelo cliente, já foi
+	// criado pelo stats handler do otelgrpc
+	ctx, span := otel.GetTracerProvider().Tracer("GetTemperature").Start(ctx, "zipcode temperature")
+	defer span.End()
+	span.SetAttributes(telemetry.BaggageAttributes(ctx)...)
+
+	scales, err := units.ParseScales(req.GetUnits())
+	if err != nil {
+		return nil, (&lookupFailure{
+			status: http.StatusBadRequest,
+			apiErr: apierror.New(apierror.CodeInvalidRequest, err.Error()).WithDetail("parameter", "units"),
+		}).grpcStatus()
+	}
+
+	address, weatherResponse, failure := s.temperature.resolve(ctx, req.GetZipcode(), s.temperature.weatherAPI.GetTempByCity)
+	if failure != nil {
+		return nil, failure.grpcStatus()
+	}
+
+	temperatures := newTemperatures(weatherResponse.Temperature, scales)
+	return &temperaturev1.GetTemperatureResponse{
+		TempC:      temperatures.TempC,
+		TempF:      temperatures.TempF,
+		TempK:      temperatures.TempK,
+		ObservedAt: timestamppb.New(weatherResponse.ObservedAt),
+		Provider:   weatherResponse.Provider,
+		Address: &temperaturev1.Address{
+			Zipcode:      address.ZipCode,
+			Street:       address.Street,
+			Complement:   address.Complement,
+			Neighborhood: address.Neighborhood,
+			City:         address.City,
+			State:        address.State,
+			Ibge:         address.IBGE,
+			Ddd:          address.DDD,
+		},
+	}, nil
+}
+
+// grpcCodes traduz o status HTTP da falha no código gRPC equivalente.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// grpcStatus converte a falha em um status gRPC que carrega o código, os
+// detalhes e o tempo de espera do envelope de erro HTTP.
+func (f *lookupFailure) grpcStatus() error {
+	code, ok := grpcCodes[f.status]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, f.apiErr.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(f.apiErr.Code), Domain: "serviceb"}}
+	if len(f.apiErr.Details) > 0 {
+		if s, err := structpb.NewStruct(f.apiErr.Details); err == nil {
+			details = append(details, s)
+		}
+	}
+	if f.retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(f.retryAfter)})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
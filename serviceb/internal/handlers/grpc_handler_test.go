@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	temperaturev1 "github.com/AndreD23/goexpert-labs-otel/api/temperature/v1"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newGRPCClient(t *testing.T, server temperaturev1.TemperatureServiceServer, tp *sdktrace.TracerProvider) temperaturev1.TemperatureServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp))))
+	temperaturev1.RegisterTemperatureServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return temperaturev1.NewTemperatureServiceClient(conn)
+}
+
+func TestGRPCServer_GetTemperature(t *testing.T) {
+	weatherResp := weatherapi.NewResponse(25)
+	weatherResp.ObservedAt = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	weatherResp.Provider = "weatherapi"
+	server := NewGRPCServer(New(&mockViaCEPService{mockResponse: "São Paulo"}, &mockWeatherAPI{mockResponse: weatherResp}))
+	client := newGRPCClient(t, server, sdktrace.NewTracerProvider())
+
+	resp, err := client.GetTemperature(context.Background(), &temperaturev1.GetTemperatureRequest{Zipcode: "01001-000", Units: "c,k"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, resp.GetTempC())
+	assert.Nil(t, resp.TempF)
+	assert.Equal(t, 298.15, resp.GetTempK())
+	assert.Equal(t, weatherResp.ObservedAt, resp.GetObservedAt().AsTime())
+	assert.Equal(t, "weatherapi", resp.GetProvider())
+	assert.Equal(t, "01001000", resp.GetAddress().GetZipcode())
+	assert.Equal(t, "São Paulo", resp.GetAddress().GetCity())
+	assert.Equal(t, "SP", resp.GetAddress().GetState())
+}
+
+func TestGRPCServer_GetTemperature_Errors(t *testing.T) {
+	tests := []struct {
+		name               string
+		zipCode            string
+		units              string
+		viaCEPErr          error
+		weatherErr         error
+		expectedCode       codes.Code
+		expectedReason     apierror.Code
+		expectedDetails    map[string]interface{}
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name:           "invalid zipcode",
+			zipCode:        "123",
+			expectedCode:   codes.InvalidArgument,
+			expectedReason: apierror.CodeInvalidZipCode,
+		},
+		{
+			name:            "unknown unit",
+			zipCode:         "01001000",
+			units:           "rankine",
+			expectedCode:    codes.InvalidArgument,
+			expectedReason:  apierror.CodeInvalidRequest,
+			expectedDetails: map[string]interface{}{"parameter": "units"},
+		},
+		{
+			name:           "zipcode not found",
+			zipCode:        "99999999",
+			viaCEPErr:      viacep.ErrNotFound,
+			expectedCode:   codes.NotFound,
+			expectedReason: apierror.CodeZipCodeNotFound,
+		},
+		{
+			name:    "rate limited",
+			zipCode: "01001000",
+			weatherErr: &utils.LookupError{Provider: "weatherapi", Err: &utils.UpstreamError{
+				StatusCode: http.StatusTooManyRequests,
+				Retryable:  true,
+				RetryAfter: 30 * time.Second,
+			}},
+			expectedCode:       codes.ResourceExhausted,
+			expectedReason:     apierror.CodeRateLimited,
+			expectedDetails:    map[string]interface{}{"provider": "weatherapi", "retry_after_seconds": 30.0},
+			expectedRetryAfter: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewGRPCServer(New(
+				&mockViaCEPService{mockResponse: "São Paulo", mockError: tt.viaCEPErr},
+				&mockWeatherAPI{mockResponse: weatherapi.NewResponse(25), mockError: tt.weatherErr},
+			))
+			client := newGRPCClient(t, server, sdktrace.NewTracerProvider())
+
+			_, err := client.GetTemperature(context.Background(), &temperaturev1.GetTemperatureRequest{Zipcode: tt.zipCode, Units: tt.units})
+
+			st := status.Convert(err)
+			assert.Equal(t, tt.expectedCode, st.Code())
+			var reason string
+			var details map[string]interface{}
+			var retryAfter time.Duration
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					reason = d.GetReason()
+				case *structpb.Struct:
+					details = d.AsMap()
+				case *errdetails.RetryInfo:
+					retryAfter = d.GetRetryDelay().AsDuration()
+				}
+			}
+			assert.Equal(t, string(tt.expectedReason), reason)
+			assert.Equal(t, tt.expectedDetails, details)
+			assert.Equal(t, tt.expectedRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestGRPCServer_TraceLinksAcrossServices(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	originalTP := otel.GetTracerProvider()
+	originalPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(originalTP)
+		otel.SetTextMapPropagator(originalPropagator)
+	}()
+
+	server := NewGRPCServer(New(&mockViaCEPService{mockResponse: "São Paulo"}, &mockWeatherAPI{mockResponse: weatherapi.NewResponse(25)}))
+	client := newGRPCClient(t, server, tp)
+
+	ctx, parent := tp.Tracer("servicea").Start(context.Background(), "zipcode validation")
+	_, err := client.GetTemperature(ctx, &temperaturev1.GetTemperatureRequest{Zipcode: "01001000"})
+	parent.End()
+	assert.NoError(t, err)
+
+	var handlerSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID(), span.Name())
+		if span.Name() == "zipcode temperature" {
+			handlerSpan = span
+		}
+	}
+	assert.NotNil(t, handlerSpan)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"net/http"
+)
+
+type HealthResponse struct {
+	Status          string            `json:"status"`
+	CircuitBreakers map[string]string `json:"circuit_breakers"`
+}
+
+type HealthHandler struct {
+	breakers []*breaker.Breaker
+}
+
+func NewHealthHandler(breakers ...*breaker.Breaker) *HealthHandler {
+	return &HealthHandler{breakers: breakers}
+}
+
+// GetHealth sempre responde 200 enquanto o processo estiver de pé; um
+// circuito aberto apenas marca o serviço como "degraded".
+func (h *HealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	resp := HealthResponse{
+		Status:          "ok",
+		CircuitBreakers: make(map[string]string, len(h.breakers)),
+	}
+	for _, b := range h.breakers {
+		state := b.State()
+		resp.CircuitBreakers[b.Name()] = state.String()
+		if state != breaker.StateClosed {
+			resp.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHealth(t *testing.T) {
+	viaCEPBreaker := breaker.New(breaker.Settings{Name: "viacep"})
+	weatherBreaker := breaker.New(breaker.Settings{Name: "weatherapi", WindowSize: 1, MinRequests: 1})
+
+	handler := NewHealthHandler(viaCEPBreaker, weatherBreaker)
+
+	tests := []struct {
+		name     string
+		setup    func()
+		expected HealthResponse
+	}{
+		{
+			name:  "all closed",
+			setup: func() {},
+			expected: HealthResponse{
+				Status:          "ok",
+				CircuitBreakers: map[string]string{"viacep": "closed", "weatherapi": "closed"},
+			},
+		},
+		{
+			name: "weatherapi open",
+			setup: func() {
+				done, _ := weatherBreaker.Allow()
+				done(errors.New("503 Service Unavailable"))
+			},
+			expected: HealthResponse{
+				Status:          "degraded",
+				CircuitBreakers: map[string]string{"viacep": "closed", "weatherapi": "open"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			w := httptest.NewRecorder()
+			handler.GetHealth(w, httptest.NewRequest("GET", "/health", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var got HealthResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -1,75 +1,318 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/common/httpapi"
+	"github.com/AndreD23/goexpert-labs-otel/common/zipcode"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/units"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/AndreD23/goexpert-labs-otel/telemetry"
 	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// Temperatures traz apenas as escalas solicitadas em ?units=.
+type Temperatures struct {
+	TempC *float64 `json:"temp_c,omitempty"`
+	TempF *float64 `json:"temp_f,omitempty"`
+	TempK *float64 `json:"temp_k,omitempty"`
+}
+
+func newTemperatures(temperature weatherapi.Temperature, scales []units.Scale) Temperatures {
+	var temperatures Temperatures
+	for _, scale := range scales {
+		switch scale {
+		case units.Celsius:
+			temperatures.TempC = &temperature.TempC
+		case units.Fahrenheit:
+			temperatures.TempF = &temperature.TempF
+		case units.Kelvin:
+			temperatures.TempK = &temperature.TempK
+		}
+	}
+	return temperatures
+}
+
+// TemperatureResponse mantém as temperaturas no nível raiz, como no contrato
+// original, e acrescenta o endereço apenas quando solicitado.
+type TemperatureResponse struct {
+	Temperatures
+	Address *viacep.Address `json:"address,omitempty"`
+}
+
+// TemperatureResponseV2 é a resposta de /v2, com a localização resolvida e
+// a origem da medição.
+type TemperatureResponseV2 struct {
+	City    string `json:"city"`
+	State   string `json:"state"`
+	ZipCode string `json:"zipcode"`
+	Temperatures
+	ObservedAt time.Time `json:"observed_at"`
+	Provider   string    `json:"provider"`
+}
+
 type TemperatureHandler struct {
 	viaCEP     viacep.ViaCEPInterface
 	weatherAPI weatherapi.WeatherAPIInterface
 }
 
-func New(weatherAPI *weatherapi.WeatherAPI) *TemperatureHandler {
+func New(viaCEP viacep.ViaCEPInterface, weatherAPI weatherapi.WeatherAPIInterface) *TemperatureHandler {
 	return &TemperatureHandler{
-		viaCEP:     viacep.NewViaCEPService(),
+		viaCEP:     viaCEP,
 		weatherAPI: weatherAPI,
 	}
 }
 
-func (t *TemperatureHandler) validateZipCode(zipCode string) (string, error) {
-	cleanZip := ""
-	for _, char := range zipCode {
-		if char >= '0' && char <= '9' {
-			cleanZip += string(char)
-		}
+func (t *TemperatureHandler) GetTemperature(w http.ResponseWriter, r *http.Request) {
+	ctx, span := startSpan(r, "zipcode temperature")
+	defer span.End()
+
+	scales, ok := parseUnits(ctx, w, r)
+	if !ok {
+		return
+	}
+	address, weatherResponse, ok := t.lookup(ctx, w, chi.URLParam(r, "zipCode"))
+	if !ok {
+		return
 	}
-	if len(cleanZip) != 8 {
-		return "", fmt.Errorf("invalid zipcode: must contain exactly 8 digits")
+
+	response := TemperatureResponse{Temperatures: newTemperatures(weatherResponse.Temperature, scales)}
+	if httpapi.Includes(r, "address") {
+		response.Address = &address
 	}
-	return cleanZip, nil
+	httpapi.WriteJSON(w, response)
 }
 
-func (t *TemperatureHandler) GetTemperature(w http.ResponseWriter, r *http.Request) {
+// GetTemperatureV2 acrescenta à temperatura a localização resolvida, o
+// horário da medição e o provedor de clima.
+func (t *TemperatureHandler) GetTemperatureV2(w http.ResponseWriter, r *http.Request) {
+	ctx, span := startSpan(r, "zipcode temperature")
+	defer span.End()
+
+	scales, ok := parseUnits(ctx, w, r)
+	if !ok {
+		return
+	}
+	address, weatherResponse, ok := t.lookup(ctx, w, chi.URLParam(r, "zipCode"))
+	if !ok {
+		return
+	}
+
+	httpapi.WriteJSON(w, TemperatureResponseV2{
+		City:         address.City,
+		State:        address.State,
+		ZipCode:      zipcode.Format(address.ZipCode),
+		Temperatures: newTemperatures(weatherResponse.Temperature, scales),
+		ObservedAt:   weatherResponse.ObservedAt,
+		Provider:     weatherResponse.Provider,
+	})
+}
+
+func startSpan(r *http.Request, name string) (context.Context, trace.Span) {
 	tr := otel.GetTracerProvider().Tracer("GetTemperature")
 
 	carrier := propagation.HeaderCarrier(r.Header)
-	ctx := r.Context()
-	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
-	ctx, span := tr.Start(ctx, "zipcode temperature")
-	defer span.End()
-
-	zipCode := chi.URLParam(r, "zipCode")
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), carrier)
+	ctx, span := tr.Start(ctx, name)
+	span.SetAttributes(telemetry.BaggageAttributes(ctx)...)
+	return ctx, span
+}
 
-	cleanZip, err := t.validateZipCode(zipCode)
+// parseUnits lê as escalas pedidas em ?units=, respondendo 400 quando alguma
+// é desconhecida.
+func parseUnits(ctx context.Context, w http.ResponseWriter, r *http.Request) ([]units.Scale, bool) {
+	scales, err := units.ParseScales(r.URL.Query().Get("units"))
 	if err != nil {
-		http.Error(w, "invalid zipcode", http.StatusUnprocessableEntity)
-		return
+		apierror.Write(ctx, w, http.StatusBadRequest, apierror.New(apierror.CodeInvalidRequest, err.Error()).
+			WithDetail("parameter", "units"))
+		return nil, false
+	}
+	return scales, true
+}
+
+// lookup resolve o endereço e a temperatura do CEP. Em caso de falha, a
+// resposta de erro já foi escrita e ok é false.
+func (t *TemperatureHandler) lookup(ctx context.Context, w http.ResponseWriter, zipCode string) (viacep.Address, weatherapi.Response, bool) {
+	address, weatherResponse, failure := t.resolve(ctx, zipCode, t.weatherAPI.GetTempByCity)
+	if failure != nil {
+		if !clientGone(ctx) {
+			failure.write(ctx, w)
+		}
+		return viacep.Address{}, weatherapi.Response{}, false
+	}
+	return address, weatherResponse, true
+}
+
+// resolve valida o CEP e consulta o endereço e, com getTemp, a temperatura.
+// Em caso de falha, devolve a resposta de erro correspondente.
+func (t *TemperatureHandler) resolve(ctx context.Context, zipCode string, getTemp func(context.Context, string) (weatherapi.Response, error)) (viacep.Address, weatherapi.Response, *lookupFailure) {
+	address, failure := t.resolveAddress(ctx, zipCode)
+	if failure != nil {
+		return viacep.Address{}, weatherapi.Response{}, failure
 	}
 
-	city, err := t.viaCEP.GetCityByZipCode(cleanZip)
+	weatherResponse, err := getTemp(ctx, address.City)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return viacep.Address{}, weatherapi.Response{}, weatherFailure(ctx, err)
 	}
-	if city == "" {
-		http.Error(w, "can not find zipcode", http.StatusNotFound)
-		return
+
+	return address, weatherResponse, nil
+}
+
+// resolveAddress valida o CEP e consulta o endereço correspondente.
+func (t *TemperatureHandler) resolveAddress(ctx context.Context, zipCode string) (viacep.Address, *lookupFailure) {
+	span := trace.SpanFromContext(ctx)
+
+	cleanZip, err := zipcode.Validate(zipCode)
+	if err != nil {
+		zipcode.RecordValidationFailure(ctx, "invalid_format")
+		return viacep.Address{}, &lookupFailure{
+			status: http.StatusUnprocessableEntity,
+			apiErr: apierror.New(apierror.CodeInvalidZipCode, "invalid zipcode"),
+		}
 	}
 
-	weatherResponse, err := t.weatherAPI.GetTempByCity(city)
+	address, err := t.viaCEP.GetAddressByZipCode(ctx, cleanZip)
+	if errors.Is(err, viacep.ErrNotFound) {
+		return viacep.Address{}, &lookupFailure{
+			status: http.StatusNotFound,
+			apiErr: apierror.New(apierror.CodeZipCodeNotFound, "can not find zipcode"),
+		}
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "viacep lookup failed")
+		return viacep.Address{}, classifyLookupError(err,
+			apierror.New(apierror.CodeZipCodeNotFound, "can not find zipcode"),
+			apierror.New(apierror.CodeZipCodeLookupFailed, "failed to look up zipcode"))
+	}
+	if address.ZipCode == "" {
+		address.ZipCode = cleanZip
+	}
+	return address, nil
+}
+
+// weatherFailure registra no span a falha da consulta ao provedor de clima e
+// devolve a resposta de erro correspondente.
+func weatherFailure(ctx context.Context, err error) *lookupFailure {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "weather lookup failed")
+	return classifyLookupError(err,
+		apierror.New(apierror.CodeCityNotFound, "can not find city"),
+		apierror.New(apierror.CodeWeatherLookupFailed, "failed to look up weather"))
+}
+
+// clientGone indica que a requisição foi cancelada pelo cliente, caso em que
+// não há para quem escrever a resposta.
+func clientGone(ctx context.Context) bool {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		trace.SpanFromContext(ctx).AddEvent("client disconnected")
+		return true
+	}
+	return false
+}
+
+// lookupFailure é a resposta de erro de uma consulta que falhou.
+type lookupFailure struct {
+	status     int
+	apiErr     *apierror.Error
+	retryAfter time.Duration
+}
+
+func (f *lookupFailure) write(ctx context.Context, w http.ResponseWriter) {
+	if f.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(f.retryAfter)))
 	}
+	apierror.Write(ctx, w, f.status, f.apiErr)
+}
+
+// classifyLookupError traduz a falha de uma consulta aos upstreams no
+// envelope de erro. notFound é usado quando o upstream não conhece o recurso
+// e fallback quando o erro não tem tratamento específico.
+func classifyLookupError(err error, notFound, fallback *apierror.Error) *lookupFailure {
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		return withRetryAfter(&lookupFailure{
+			status: http.StatusServiceUnavailable,
+			apiErr: apierror.New(apierror.CodeUpstreamUnavailable, openErr.Name+" is temporarily unavailable, please try again later").
+				WithDetail("upstream", openErr.Name),
+		}, openErr.RetryAfter)
+	}
+
+	var upstreamErr *utils.UpstreamError
+	errors.As(err, &upstreamErr)
+	provider := lookupProvider(err)
+	name := provider
+	if name == "" {
+		name = "upstream"
+	}
+
+	switch {
+	case errors.Is(err, weatherapi.ErrCityNotFound),
+		upstreamErr != nil && upstreamErr.StatusCode == http.StatusNotFound:
+		return &lookupFailure{status: http.StatusNotFound, apiErr: notFound}
+	case upstreamErr != nil && upstreamErr.StatusCode == http.StatusTooManyRequests:
+		return withRetryAfter(&lookupFailure{
+			status: http.StatusTooManyRequests,
+			apiErr: apierror.New(apierror.CodeRateLimited, name+" rate limit exceeded, please try again later").
+				WithDetail("provider", name),
+		}, upstreamErr.RetryAfter)
+	case isTimeout(err):
+		return &lookupFailure{
+			status: http.StatusGatewayTimeout,
+			apiErr: apierror.New(apierror.CodeUpstreamTimeout, name+" did not respond in time").
+				WithDetail("provider", name),
+		}
+	default:
+		if provider != "" {
+			fallback.WithDetail("provider", provider)
+		}
+		if upstreamErr != nil {
+			fallback.WithDetail("upstream_status", upstreamErr.StatusCode)
+		}
+		return &lookupFailure{status: http.StatusInternalServerError, apiErr: fallback}
+	}
+}
+
+// lookupProvider devolve o nome do provedor que originou err, se conhecido.
+func lookupProvider(err error) string {
+	var lookupErr *utils.LookupError
+	if errors.As(err, &lookupErr) {
+		return lookupErr.Provider
+	}
+	return ""
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func withRetryAfter(failure *lookupFailure, retryAfter time.Duration) *lookupFailure {
+	if retryAfter > 0 {
+		failure.retryAfter = retryAfter
+		failure.apiErr.WithDetail("retry_after_seconds", retryAfterSeconds(retryAfter))
+	}
+	return failure
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(weatherResponse.Temperature)
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
 }
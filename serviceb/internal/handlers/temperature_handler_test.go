@@ -1,83 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/common/apierror"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/viacep"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/weatherapi"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestValidateZipCode(t *testing.T) {
-	tests := []struct {
-		name      string
-		zipCode   string
-		want      string
-		expectErr bool
-	}{
-		{
-			name:      "valid 8 digits",
-			zipCode:   "12345678",
-			want:      "12345678",
-			expectErr: false,
-		},
-		{
-			name:      "contains letters",
-			zipCode:   "1234abcd",
-			want:      "",
-			expectErr: true,
-		},
-		{
-			name:      "too short",
-			zipCode:   "12345",
-			want:      "",
-			expectErr: true,
-		},
-		{
-			name:      "too long",
-			zipCode:   "123456789",
-			want:      "",
-			expectErr: true,
-		},
-		{
-			name:      "contains special chars",
-			zipCode:   "1234#678",
-			want:      "",
-			expectErr: true,
-		},
-		{
-			name:      "contains dash char",
-			zipCode:   "12345-678",
-			want:      "12345678",
-			expectErr: false,
-		},
-		{
-			name:      "empty string",
-			zipCode:   "",
-			want:      "",
-			expectErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			th := &TemperatureHandler{}
-			got, err := th.validateZipCode(tt.zipCode)
-
-			if (err != nil) != tt.expectErr {
-				t.Errorf("validateZipCode() error = %v, expectErr %v", err, tt.expectErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("validateZipCode() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 // Mock do ViaCEP Service
 type mockViaCEPService struct {
 	mockResponse string
@@ -90,11 +37,14 @@ type mockWeatherAPI struct {
 	mockError    error
 }
 
-func (m *mockViaCEPService) GetCityByZipCode(zipCode string) (string, error) {
-	return m.mockResponse, m.mockError
+func (m *mockViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (viacep.Address, error) {
+	if m.mockError != nil {
+		return viacep.Address{}, m.mockError
+	}
+	return viacep.Address{ZipCode: zipCode, City: m.mockResponse, State: "SP"}, nil
 }
 
-func (m *mockWeatherAPI) GetTempByCity(city string) (weatherapi.Response, error) {
+func (m *mockWeatherAPI) GetTempByCity(ctx context.Context, city string) (weatherapi.Response, error) {
 	return m.mockResponse, m.mockError
 }
 
@@ -143,7 +93,7 @@ func TestGetTemperature(t *testing.T) {
 			mockWeatherResp:  weatherapi.Response{},
 			mockWeatherError: nil,
 			expectedStatus:   http.StatusUnprocessableEntity,
-			expectedResponse: "invalid zipcode",
+			expectedResponse: `"code":"INVALID_ZIPCODE"`,
 		},
 		{
 			name:             "ViaCEP Error",
@@ -153,7 +103,7 @@ func TestGetTemperature(t *testing.T) {
 			mockWeatherResp:  weatherapi.Response{},
 			mockWeatherError: nil,
 			expectedStatus:   http.StatusInternalServerError,
-			expectedResponse: `{"error":"viacep error"}`,
+			expectedResponse: `"code":"ZIPCODE_LOOKUP_FAILED"`,
 		},
 		{
 			name:             "Weather API Error",
@@ -163,7 +113,7 @@ func TestGetTemperature(t *testing.T) {
 			mockWeatherResp:  weatherapi.Response{},
 			mockWeatherError: errors.New("weather api error"),
 			expectedStatus:   http.StatusInternalServerError,
-			expectedResponse: `{"error":"weather api error"}`,
+			expectedResponse: `"code":"WEATHER_LOOKUP_FAILED"`,
 		},
 	}
 
@@ -214,3 +164,372 @@ func TestGetTemperature(t *testing.T) {
 		})
 	}
 }
+
+// Mock que bloqueia até o contexto ser cancelado
+type blockingViaCEPService struct {
+	started chan struct{}
+	ctxErr  chan error
+}
+
+func (m *blockingViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (viacep.Address, error) {
+	close(m.started)
+	<-ctx.Done()
+	m.ctxErr <- ctx.Err()
+	return viacep.Address{}, ctx.Err()
+}
+
+func TestGetTemperature_ClientCancellation(t *testing.T) {
+	mockViaCEP := &blockingViaCEPService{
+		started: make(chan struct{}),
+		ctxErr:  make(chan error, 1),
+	}
+	handler := New(mockViaCEP, &mockWeatherAPI{})
+	router := setupRouter(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/temperature/12345678", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	<-mockViaCEP.started
+	cancel()
+	<-done
+
+	assert.ErrorIs(t, <-mockViaCEP.ctxErr, context.Canceled)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestGetTemperature_BaggageAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	originalTP := otel.GetTracerProvider()
+	originalPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer func() {
+		otel.SetTracerProvider(originalTP)
+		otel.SetTextMapPropagator(originalPropagator)
+	}()
+
+	handler := New(&mockViaCEPService{mockResponse: "São Paulo"}, &mockWeatherAPI{})
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("GET", "/temperature/12345678", nil)
+	req.Header.Set("baggage", "tenant=acme,client_id=42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("baggage.tenant", "acme"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("baggage.client_id", "42"))
+}
+
+func TestGetTemperature_CircuitOpen(t *testing.T) {
+	handler := New(
+		&mockViaCEPService{mockResponse: "São Paulo"},
+		&mockWeatherAPI{mockError: &breaker.OpenError{Name: "weatherapi", RetryAfter: 1500 * time.Millisecond}},
+	)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("GET", "/temperature/12345678", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "weatherapi is temporarily unavailable")
+}
+
+func TestGetTemperature_ErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name           string
+		viaCEP         *mockViaCEPService
+		weather        *mockWeatherAPI
+		expectedStatus int
+		expected       apierror.Error
+	}{
+		{
+			name:           "cep provider failure",
+			viaCEP:         &mockViaCEPService{mockError: &utils.LookupError{Provider: "brasilapi", Err: errors.New("500 Internal Server Error")}},
+			weather:        &mockWeatherAPI{},
+			expectedStatus: http.StatusInternalServerError,
+			expected: apierror.Error{
+				Code:    apierror.CodeZipCodeLookupFailed,
+				Message: "failed to look up zipcode",
+				Details: map[string]interface{}{"provider": "brasilapi"},
+			},
+		},
+		{
+			name:           "zipcode not found",
+			viaCEP:         &mockViaCEPService{mockError: viacep.ErrNotFound},
+			weather:        &mockWeatherAPI{},
+			expectedStatus: http.StatusNotFound,
+			expected:       apierror.Error{Code: apierror.CodeZipCodeNotFound, Message: "can not find zipcode"},
+		},
+		{
+			name:   "city not found",
+			viaCEP: &mockViaCEPService{mockResponse: "Cidade Inexistente"},
+			weather: &mockWeatherAPI{mockError: &utils.LookupError{
+				Provider: "openmeteo",
+				Err:      fmt.Errorf("%w: Cidade Inexistente", weatherapi.ErrCityNotFound),
+			}},
+			expectedStatus: http.StatusNotFound,
+			expected:       apierror.Error{Code: apierror.CodeCityNotFound, Message: "can not find city"},
+		},
+		{
+			name: "cep provider not found",
+			viaCEP: &mockViaCEPService{mockError: &utils.LookupError{
+				Provider: "brasilapi",
+				Err:      &utils.UpstreamError{Upstream: "brasilapi.com.br", StatusCode: http.StatusNotFound},
+			}},
+			weather:        &mockWeatherAPI{},
+			expectedStatus: http.StatusNotFound,
+			expected:       apierror.Error{Code: apierror.CodeZipCodeNotFound, Message: "can not find zipcode"},
+		},
+		{
+			name:   "weather rate limited",
+			viaCEP: &mockViaCEPService{mockResponse: "São Paulo"},
+			weather: &mockWeatherAPI{mockError: &utils.LookupError{
+				Provider: "weatherapi",
+				Err:      &utils.UpstreamError{Upstream: "api.weatherapi.com", StatusCode: http.StatusTooManyRequests, Retryable: true, RetryAfter: 30 * time.Second},
+			}},
+			expectedStatus: http.StatusTooManyRequests,
+			expected: apierror.Error{
+				Code:    apierror.CodeRateLimited,
+				Message: "weatherapi rate limit exceeded, please try again later",
+				Details: map[string]interface{}{"provider": "weatherapi", "retry_after_seconds": float64(30)},
+			},
+		},
+		{
+			name:   "weather timeout",
+			viaCEP: &mockViaCEPService{mockResponse: "São Paulo"},
+			weather: &mockWeatherAPI{mockError: &utils.LookupError{
+				Provider: "weatherapi",
+				Err:      fmt.Errorf("Get \"https://api.weatherapi.com\": %w", context.DeadlineExceeded),
+			}},
+			expectedStatus: http.StatusGatewayTimeout,
+			expected: apierror.Error{
+				Code:    apierror.CodeUpstreamTimeout,
+				Message: "weatherapi did not respond in time",
+				Details: map[string]interface{}{"provider": "weatherapi"},
+			},
+		},
+		{
+			name: "cep provider server error",
+			viaCEP: &mockViaCEPService{mockError: &utils.LookupError{
+				Provider: "viacep",
+				Err:      &utils.UpstreamError{Upstream: "viacep.com.br", StatusCode: http.StatusBadGateway, Retryable: true},
+			}},
+			weather:        &mockWeatherAPI{},
+			expectedStatus: http.StatusInternalServerError,
+			expected: apierror.Error{
+				Code:    apierror.CodeZipCodeLookupFailed,
+				Message: "failed to look up zipcode",
+				Details: map[string]interface{}{"provider": "viacep", "upstream_status": float64(http.StatusBadGateway)},
+			},
+		},
+		{
+			name:           "circuit open",
+			viaCEP:         &mockViaCEPService{mockError: &breaker.OpenError{Name: "viacep"}},
+			weather:        &mockWeatherAPI{},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: apierror.Error{
+				Code:    apierror.CodeUpstreamUnavailable,
+				Message: "viacep is temporarily unavailable, please try again later",
+				Details: map[string]interface{}{"upstream": "viacep"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(New(tt.viaCEP, tt.weather))
+
+			req := httptest.NewRequest("GET", "/temperature/12345678", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.expected.Code, got.Code)
+			assert.Equal(t, tt.expected.Message, got.Message)
+			assert.Equal(t, tt.expected.Details, got.Details)
+		})
+	}
+}
+
+type weatherAPIErrorTransport struct{}
+
+func (weatherAPIErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := `{"error":{"code":1006,"message":"No matching location found."}}`
+	return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestGetTemperature_WeatherAPIUnknownCity(t *testing.T) {
+	weather := weatherapi.NewWeatherAPI("test_api_key", &http.Client{Transport: weatherAPIErrorTransport{}}, time.Second)
+	router := setupRouter(New(&mockViaCEPService{mockResponse: "Cidade Inexistente"}, weather))
+
+	req := httptest.NewRequest("GET", "/temperature/12345678", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var got apierror.Error
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, apierror.CodeCityNotFound, got.Code)
+	assert.Equal(t, "can not find city", got.Message)
+}
+
+func TestGetTemperature_IncludeAddress(t *testing.T) {
+	weather := &mockWeatherAPI{mockResponse: weatherapi.NewResponse(25)}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "without include",
+			query:    "",
+			expected: `{"temp_c":25,"temp_f":77,"temp_k":298.15}`,
+		},
+		{
+			name:     "include address",
+			query:    "?include=address",
+			expected: `{"temp_c":25,"temp_f":77,"temp_k":298.15,"address":{"cep":"01001000","logradouro":"","bairro":"","localidade":"São Paulo","uf":"SP"}}`,
+		},
+		{
+			name:     "include list",
+			query:    "?include=unknown,%20address",
+			expected: `{"temp_c":25,"temp_f":77,"temp_k":298.15,"address":{"cep":"01001000","logradouro":"","bairro":"","localidade":"São Paulo","uf":"SP"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(New(&mockViaCEPService{mockResponse: "São Paulo"}, weather))
+
+			req := httptest.NewRequest("GET", "/temperature/01001000"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestGetTemperature_Units(t *testing.T) {
+	tests := []struct {
+		name           string
+		tempC          float64
+		query          string
+		expectedStatus int
+		expected       string
+	}{
+		{
+			name:           "all scales by default",
+			tempC:          25,
+			expectedStatus: http.StatusOK,
+			expected:       `{"temp_c":25,"temp_f":77,"temp_k":298.15}`,
+		},
+		{
+			name:           "only kelvin",
+			tempC:          25,
+			query:          "?units=k",
+			expectedStatus: http.StatusOK,
+			expected:       `{"temp_k":298.15}`,
+		},
+		{
+			name:           "celsius and fahrenheit",
+			tempC:          20.2,
+			query:          "?units=fahrenheit,celsius",
+			expectedStatus: http.StatusOK,
+			expected:       `{"temp_c":20.2,"temp_f":68.36}`,
+		},
+		{
+			name:           "zero is not omitted",
+			tempC:          0,
+			query:          "?units=c",
+			expectedStatus: http.StatusOK,
+			expected:       `{"temp_c":0}`,
+		},
+		{
+			name:           "unknown scale",
+			tempC:          25,
+			query:          "?units=rankine",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weather := &mockWeatherAPI{mockResponse: weatherapi.NewResponse(tt.tempC)}
+			router := setupRouter(New(&mockViaCEPService{mockResponse: "São Paulo"}, weather))
+
+			req := httptest.NewRequest("GET", "/temperature/01001000"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, tt.expected, w.Body.String())
+				return
+			}
+			var got apierror.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, apierror.CodeInvalidRequest, got.Code)
+			assert.Equal(t, map[string]interface{}{"parameter": "units"}, got.Details)
+		})
+	}
+}
+
+func TestGetTemperatureV2(t *testing.T) {
+	weatherResp := weatherapi.NewResponse(25)
+	weatherResp.ObservedAt = time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+	weatherResp.Provider = "openmeteo"
+
+	r := chi.NewRouter()
+	r.Get("/v2/{zipCode}", New(&mockViaCEPService{mockResponse: "São Paulo"}, &mockWeatherAPI{mockResponse: weatherResp}).GetTemperatureV2)
+
+	req := httptest.NewRequest("GET", "/v2/01001000", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"city": "São Paulo",
+		"state": "SP",
+		"zipcode": "01001-000",
+		"temp_c": 25,
+		"temp_f": 77,
+		"temp_k": 298.15,
+		"observed_at": "2025-10-18T12:00:00Z",
+		"provider": "openmeteo"
+	}`, w.Body.String())
+}
+
+func TestGetTemperature_ErrorTraceID(t *testing.T) {
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer otel.SetTracerProvider(originalTP)
+
+	router := setupRouter(New(&mockViaCEPService{}, &mockWeatherAPI{}))
+
+	req := httptest.NewRequest("GET", "/temperature/1234", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var got apierror.Error
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, apierror.CodeInvalidZipCode, got.Code)
+	assert.Len(t, got.TraceID, 32)
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"strconv"
+	"time"
+)
+
+const instrumentationName = "github.com/AndreD23/goexpert-labs-otel/serviceb"
+
+type recorder struct {
+	upstreamDuration metric.Float64Histogram
+	upstreamErrors   metric.Int64Counter
+	cacheLookups     metric.Int64Counter
+}
+
+// defaultRecorder usa o provider global, que delega os instrumentos ao
+// provider configurado por telemetry.Setup assim que ele for registrado.
+var defaultRecorder = newRecorder(otel.GetMeterProvider())
+
+func newRecorder(provider metric.MeterProvider) *recorder {
+	meter := provider.Meter(instrumentationName)
+	r := &recorder{}
+
+	var err error
+	r.upstreamDuration, err = meter.Float64Histogram(
+		"upstream.request.duration",
+		metric.WithDescription("Duration of calls to upstream providers such as ViaCEP and WeatherAPI."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	r.upstreamErrors, err = meter.Int64Counter(
+		"upstream.request.errors",
+		metric.WithDescription("Number of failed calls to upstream providers."),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	r.cacheLookups, err = meter.Int64Counter(
+		"cache.lookups",
+		metric.WithDescription("Number of cache lookups by result."),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return r
+}
+
+// RecordUpstreamCall registra a latência de uma chamada ao upstream iniciada
+// em start e, se err não for nil, incrementa o contador de erros.
+func RecordUpstreamCall(ctx context.Context, upstream string, start time.Time, err error) {
+	defaultRecorder.recordUpstreamCall(ctx, upstream, start, err)
+}
+
+func RecordCacheLookup(ctx context.Context, cache string, hit bool) {
+	defaultRecorder.recordCacheLookup(ctx, cache, hit)
+}
+
+func (r *recorder) recordUpstreamCall(ctx context.Context, upstream string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		r.upstreamErrors.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("upstream", upstream),
+			attribute.String("error.type", errorType(err)),
+		))
+	}
+
+	r.upstreamDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("upstream", upstream),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func (r *recorder) recordCacheLookup(ctx context.Context, cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	r.cacheLookups.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache", cache),
+		attribute.String("result", result),
+	))
+}
+
+// RegisterBreakers publica o estado de cada circuit breaker no gauge
+// circuit_breaker.state (0 fechado, 1 half-open, 2 aberto).
+func RegisterBreakers(breakers ...*breaker.Breaker) error {
+	meter := otel.Meter(instrumentationName)
+	_, err := meter.Int64ObservableGauge(
+		"circuit_breaker.state",
+		metric.WithDescription("Circuit breaker state: 0 closed, 1 half-open, 2 open."),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			for _, b := range breakers {
+				o.Observe(int64(b.State()), metric.WithAttributes(attribute.String("name", b.Name())))
+			}
+			return nil
+		}),
+	)
+	return err
+}
+
+// errorType usa o status HTTP quando o upstream respondeu com erro, seguindo
+// a convenção semântica de error.type.
+func errorType(err error) string {
+	var upstreamErr *utils.UpstreamError
+	switch {
+	case errors.As(err, &upstreamErr):
+		return strconv.Itoa(upstreamErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRecordUpstreamCall(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	r := newRecorder(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	start := time.Now()
+	r.recordUpstreamCall(ctx, "viacep", start, nil)
+	r.recordUpstreamCall(ctx, "weatherapi", start, context.DeadlineExceeded)
+	r.recordUpstreamCall(ctx, "weatherapi", start, errors.New("500 Internal Server Error"))
+	r.recordUpstreamCall(ctx, "weatherapi", start, &utils.UpstreamError{StatusCode: http.StatusTooManyRequests})
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(ctx, &rm))
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	duration := metrics["upstream.request.duration"].(metricdata.Histogram[float64])
+	assert.Len(t, duration.DataPoints, 2)
+
+	errorsByType := map[string]int64{}
+	for _, dp := range metrics["upstream.request.errors"].(metricdata.Sum[int64]).DataPoints {
+		upstream, _ := dp.Attributes.Value(attribute.Key("upstream"))
+		assert.Equal(t, "weatherapi", upstream.AsString())
+		errorType, _ := dp.Attributes.Value(attribute.Key("error.type"))
+		errorsByType[errorType.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"timeout": 1, "error": 1, "429": 1}, errorsByType)
+}
@@ -0,0 +1,42 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BreakerViaCEPService deixa de chamar o ViaCEP enquanto o circuito estiver
+// aberto, devolvendo um erro que satisfaz errors.Is(err, breaker.ErrOpen).
+type BreakerViaCEPService struct {
+	next    ViaCEPInterface
+	breaker *breaker.Breaker
+}
+
+func NewBreakerViaCEPService(next ViaCEPInterface, b *breaker.Breaker) ViaCEPInterface {
+	return &BreakerViaCEPService{
+		next:    next,
+		breaker: b,
+	}
+}
+
+func (s *BreakerViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	span := trace.SpanFromContext(ctx)
+	done, err := s.breaker.Allow()
+	span.SetAttributes(attribute.String("circuit_breaker."+s.breaker.Name()+".state", s.breaker.State().String()))
+	if err != nil {
+		return Address{}, err
+	}
+
+	address, err := s.next.GetAddressByZipCode(ctx, zipCode)
+	if errors.Is(err, ErrNotFound) {
+		// CEP inexistente é uma resposta válida do provedor.
+		done(nil)
+	} else {
+		done(err)
+	}
+
+	return address, err
+}
@@ -0,0 +1,88 @@
+package viacep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/cache"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// CachedViaCEPService guarda o resultado das consultas de CEP. CEPs
+// desconhecidos (ErrNotFound) também são guardados, por negativeTTL, para
+// que CEPs inválidos repetidos não voltem ao ViaCEP.
+type CachedViaCEPService struct {
+	next        ViaCEPInterface
+	store       cache.Store
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+type cacheEntry struct {
+	Address  Address `json:"address"`
+	NotFound bool    `json:"not_found,omitempty"`
+}
+
+func NewCachedViaCEPService(next ViaCEPInterface, store cache.Store, ttl, negativeTTL time.Duration) ViaCEPInterface {
+	return &CachedViaCEPService{
+		next:        next,
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func (s *CachedViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	span := trace.SpanFromContext(ctx)
+	key := "viacep:" + zipCode
+
+	entry, found := s.lookup(ctx, key)
+	span.SetAttributes(attribute.Bool("viacep.cache.hit", found))
+	metrics.RecordCacheLookup(ctx, "viacep", found)
+	if found {
+		if entry.NotFound {
+			return Address{}, ErrNotFound
+		}
+		return entry.Address, nil
+	}
+
+	address, err := s.next.GetAddressByZipCode(ctx, zipCode)
+	notFound := errors.Is(err, ErrNotFound)
+	if err != nil && !notFound {
+		return Address{}, err
+	}
+
+	ttl := s.ttl
+	if notFound {
+		ttl = s.negativeTTL
+	}
+	if ttl > 0 {
+		value, _ := json.Marshal(cacheEntry{Address: address, NotFound: notFound})
+		if err := s.store.Set(ctx, key, value, ttl); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	return address, err
+}
+
+// lookup trata entradas ilegíveis, como as gravadas por versões anteriores,
+// como ausentes.
+func (s *CachedViaCEPService) lookup(ctx context.Context, key string) (cacheEntry, bool) {
+	var entry cacheEntry
+	value, found, err := s.store.Get(ctx, key)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+	if !found {
+		return entry, false
+	}
+	if err := json.Unmarshal(value, &entry); err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+		return entry, false
+	}
+	return entry, true
+}
@@ -0,0 +1,91 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingViaCEPService struct {
+	city  string
+	err   error
+	calls int
+}
+
+func (c *countingViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	c.calls++
+	if c.err != nil {
+		return Address{}, c.err
+	}
+	return Address{ZipCode: zipCode, City: c.city, State: "SP"}, nil
+}
+
+func TestCachedViaCEPService(t *testing.T) {
+	tests := []struct {
+		name          string
+		city          string
+		err           error
+		negativeTTL   time.Duration
+		expectedCalls int
+	}{
+		{
+			name:          "caches found city",
+			city:          "São Paulo",
+			expectedCalls: 1,
+		},
+		{
+			name:          "caches unknown zipcode",
+			err:           ErrNotFound,
+			negativeTTL:   time.Minute,
+			expectedCalls: 1,
+		},
+		{
+			name:          "negative caching disabled",
+			err:           ErrNotFound,
+			negativeTTL:   0,
+			expectedCalls: 2,
+		},
+		{
+			name:          "does not cache errors",
+			err:           errors.New("viacep error"),
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingViaCEPService{city: tt.city, err: tt.err}
+			service := NewCachedViaCEPService(next, cache.NewLRU(10), time.Hour, tt.negativeTTL)
+
+			for i := 0; i < 2; i++ {
+				address, err := service.GetAddressByZipCode(context.Background(), "01001000")
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, Address{ZipCode: "01001000", City: tt.city, State: "SP"}, address)
+				}
+			}
+
+			assert.Equal(t, tt.expectedCalls, next.calls)
+		})
+	}
+}
+
+func TestCachedViaCEPService_IgnoresUnreadableEntry(t *testing.T) {
+	store := cache.NewLRU(10)
+	assert.NoError(t, store.Set(context.Background(), "viacep:01001000", []byte("São Paulo"), time.Hour))
+
+	next := &countingViaCEPService{city: "São Paulo"}
+	service := NewCachedViaCEPService(next, store, time.Hour, 0)
+
+	address, err := service.GetAddressByZipCode(context.Background(), "01001000")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "São Paulo", address.City)
+	assert.Equal(t, 1, next.calls)
+}
@@ -0,0 +1,139 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type Strategy string
+
+const (
+	// StrategySequential consulta um provedor por vez, passando ao próximo
+	// apenas quando o anterior falha.
+	StrategySequential Strategy = "sequential"
+	// StrategyHedged dispara o próximo provedor se o anterior não responder
+	// dentro de hedgeDelay, ficando com a primeira resposta de sucesso.
+	StrategyHedged Strategy = "hedged"
+)
+
+var ErrNoProviders = errors.New("no cep providers configured")
+
+// ProviderChain resolve o CEP usando uma lista de provedores em ordem de
+// preferência. Um CEP não encontrado (ErrNotFound) é uma resposta válida e
+// encerra a busca; apenas os demais erros fazem a cadeia seguir para o próximo.
+type ProviderChain struct {
+	providers  []Provider
+	strategy   Strategy
+	hedgeDelay time.Duration
+}
+
+func NewProviderChain(strategy Strategy, hedgeDelay time.Duration, providers ...Provider) (*ProviderChain, error) {
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	switch strategy {
+	case StrategySequential, StrategyHedged:
+	default:
+		return nil, fmt.Errorf("unknown cep strategy %q", strategy)
+	}
+	return &ProviderChain{
+		providers:  providers,
+		strategy:   strategy,
+		hedgeDelay: hedgeDelay,
+	}, nil
+}
+
+func (c *ProviderChain) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	if c.strategy == StrategyHedged {
+		return c.hedged(ctx, zipCode)
+	}
+	return c.sequential(ctx, zipCode)
+}
+
+func (c *ProviderChain) sequential(ctx context.Context, zipCode string) (Address, error) {
+	var lastErr error
+	for _, provider := range c.providers {
+		address, err := provider.Service.GetAddressByZipCode(ctx, zipCode)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			annotateProvider(ctx, provider.Name)
+			return address, err
+		}
+		lastErr = err
+		recordProviderFailure(ctx, provider.Name, err)
+		if ctx.Err() != nil {
+			return Address{}, err
+		}
+	}
+	return Address{}, lastErr
+}
+
+type chainResult struct {
+	provider string
+	address  Address
+	err      error
+}
+
+func (c *ProviderChain) hedged(ctx context.Context, zipCode string) (Address, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan chainResult, len(c.providers))
+	launched, pending := 0, 0
+	launch := func() {
+		provider := c.providers[launched]
+		launched++
+		pending++
+		go func() {
+			address, err := provider.Service.GetAddressByZipCode(ctx, zipCode)
+			results <- chainResult{provider: provider.Name, address: address, err: err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil || errors.Is(result.err, ErrNotFound) {
+				annotateProvider(ctx, result.provider)
+				return result.address, result.err
+			}
+			lastErr = result.err
+			recordProviderFailure(ctx, result.provider, result.err)
+			if launched < len(c.providers) && ctx.Err() == nil {
+				launch()
+				timer.Reset(c.hedgeDelay)
+			}
+		case <-timer.C:
+			if launched < len(c.providers) {
+				trace.SpanFromContext(ctx).AddEvent("cep.hedge", trace.WithAttributes(
+					attribute.String("cep.provider", c.providers[launched].Name),
+				))
+				launch()
+				timer.Reset(c.hedgeDelay)
+			}
+		case <-ctx.Done():
+			return Address{}, ctx.Err()
+		}
+	}
+	return Address{}, lastErr
+}
+
+func annotateProvider(ctx context.Context, provider string) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("cep.provider", provider))
+}
+
+func recordProviderFailure(ctx context.Context, provider string, err error) {
+	trace.SpanFromContext(ctx).AddEvent("cep.provider.failed", trace.WithAttributes(
+		attribute.String("cep.provider", provider),
+		attribute.String("error.message", err.Error()),
+	))
+}
@@ -0,0 +1,162 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeProvider struct {
+	city  string
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (f *fakeProvider) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	f.calls.Add(1)
+	select {
+	case <-time.After(f.delay):
+		return Address{City: f.city}, f.err
+	case <-ctx.Done():
+		return Address{}, ctx.Err()
+	}
+}
+
+func TestProviderChain(t *testing.T) {
+	failure := errors.New("503 Service Unavailable")
+
+	tests := []struct {
+		name             string
+		strategy         Strategy
+		providers        []*fakeProvider
+		expectedCity     string
+		expectErr        bool
+		expectedErr      error
+		expectedProvider string
+		expectedCalls    []int32
+	}{
+		{
+			name:             "sequential uses first provider",
+			strategy:         StrategySequential,
+			providers:        []*fakeProvider{{city: "São Paulo"}, {city: "São Paulo"}},
+			expectedCity:     "São Paulo",
+			expectedProvider: "first",
+			expectedCalls:    []int32{1, 0},
+		},
+		{
+			name:             "sequential falls back on error",
+			strategy:         StrategySequential,
+			providers:        []*fakeProvider{{err: failure}, {city: "Curitiba"}},
+			expectedCity:     "Curitiba",
+			expectedProvider: "second",
+			expectedCalls:    []int32{1, 1},
+		},
+		{
+			name:             "not found stops the chain",
+			strategy:         StrategySequential,
+			providers:        []*fakeProvider{{err: ErrNotFound}, {city: "Curitiba"}},
+			expectedErr:      ErrNotFound,
+			expectedProvider: "first",
+			expectedCalls:    []int32{1, 0},
+		},
+		{
+			name:             "hedged not found stops the chain",
+			strategy:         StrategyHedged,
+			providers:        []*fakeProvider{{err: ErrNotFound}, {city: "Curitiba"}},
+			expectedErr:      ErrNotFound,
+			expectedProvider: "first",
+			expectedCalls:    []int32{1, 0},
+		},
+		{
+			name:          "all providers fail",
+			strategy:      StrategySequential,
+			providers:     []*fakeProvider{{err: failure}, {err: failure}},
+			expectErr:     true,
+			expectedCalls: []int32{1, 1},
+		},
+		{
+			name:             "hedged fires next provider after delay",
+			strategy:         StrategyHedged,
+			providers:        []*fakeProvider{{city: "Recife", delay: time.Second}, {city: "Recife"}},
+			expectedCity:     "Recife",
+			expectedProvider: "second",
+			expectedCalls:    []int32{1, 1},
+		},
+		{
+			name:             "hedged does not fire when first is fast",
+			strategy:         StrategyHedged,
+			providers:        []*fakeProvider{{city: "Recife"}, {city: "Recife"}},
+			expectedCity:     "Recife",
+			expectedProvider: "first",
+			expectedCalls:    []int32{1, 0},
+		},
+		{
+			name:             "hedged falls back immediately on error",
+			strategy:         StrategyHedged,
+			providers:        []*fakeProvider{{err: failure}, {city: "Natal"}},
+			expectedCity:     "Natal",
+			expectedProvider: "second",
+			expectedCalls:    []int32{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			ctx, span := tp.Tracer("test").Start(context.Background(), "zipcode temperature")
+
+			names := []string{"first", "second"}
+			var providers []Provider
+			for i, p := range tt.providers {
+				providers = append(providers, Provider{Name: names[i], Service: p})
+			}
+			chain, err := NewProviderChain(tt.strategy, 50*time.Millisecond, providers...)
+			assert.NoError(t, err)
+
+			address, err := chain.GetAddressByZipCode(ctx, "01001000")
+			span.End()
+
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.expectErr:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCity, address.City)
+			}
+
+			var calls []int32
+			for _, p := range tt.providers {
+				calls = append(calls, p.calls.Load())
+			}
+			assert.Equal(t, tt.expectedCalls, calls)
+
+			if tt.expectedProvider != "" {
+				var provider string
+				for _, attr := range recorder.Ended()[0].Attributes() {
+					if attr.Key == "cep.provider" {
+						provider = attr.Value.AsString()
+					}
+				}
+				assert.Equal(t, tt.expectedProvider, provider)
+			}
+		})
+	}
+}
+
+func TestNewProviderChain_Invalid(t *testing.T) {
+	_, err := NewProviderChain(StrategySequential, 0)
+	assert.ErrorIs(t, err, ErrNoProviders)
+
+	_, err = NewProviderChain("random", 0, Provider{Name: "viacep", Service: &fakeProvider{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package viacep
+
+import "errors"
+
+// ErrNotFound indica que o provedor respondeu, mas não conhece o CEP. É uma
+// resposta válida: não conta como falha do provedor nem faz a cadeia seguir.
+var ErrNotFound = errors.New("zipcode not found")
@@ -0,0 +1,105 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/metrics"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"net/http"
+	"time"
+)
+
+// Provider é uma fonte de resolução de CEP identificada pelo nome, usado nos
+// spans, métricas e circuit breakers.
+type Provider struct {
+	Name    string
+	Service ViaCEPInterface
+}
+
+func NewProvider(name string, client *http.Client, timeout time.Duration) (Provider, error) {
+	var service ViaCEPInterface
+	switch name {
+	case "viacep":
+		service = NewViaCEPService(client, timeout)
+	case "brasilapi":
+		service = &BrasilAPIService{Client: client, Timeout: timeout}
+	case "opencep":
+		service = &OpenCEPService{Client: client, Timeout: timeout}
+	default:
+		return Provider{}, fmt.Errorf("unknown cep provider %q", name)
+	}
+	return Provider{Name: name, Service: service}, nil
+}
+
+type BrasilAPIService struct {
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+type brasilAPIAddress struct {
+	Cep          string `json:"cep"`
+	State        string `json:"state"`
+	City         string `json:"city"`
+	Neighborhood string `json:"neighborhood"`
+	Street       string `json:"street"`
+}
+
+func (s *BrasilAPIService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	var data brasilAPIAddress
+	err := fetch(ctx, s.Client, s.Timeout, "brasilapi", "https://brasilapi.com.br/api/cep/v1/"+zipCode, &data)
+	if err != nil {
+		return Address{}, err
+	}
+	if data.City == "" {
+		return Address{}, ErrNotFound
+	}
+	return Address{
+		ZipCode:      data.Cep,
+		Street:       data.Street,
+		Neighborhood: data.Neighborhood,
+		City:         data.City,
+		State:        data.State,
+	}, nil
+}
+
+type OpenCEPService struct {
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+func (s *OpenCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	var data CepData
+	err := fetch(ctx, s.Client, s.Timeout, "opencep", "https://opencep.com/v1/"+zipCode, &data)
+	if err != nil {
+		return Address{}, err
+	}
+	return data.Address()
+}
+
+func fetch(ctx context.Context, client *http.Client, timeout time.Duration, provider, url string, target interface{}) error {
+	start := time.Now()
+	err := utils.FetchWithTimeout(ctx, client, timeout, url, target)
+
+	return recordLookup(ctx, provider, start, err)
+}
+
+// recordLookup registra a chamada ao provedor e envolve a falha em
+// utils.LookupError. Um 404 é tratado como CEP inexistente: ErrNotFound é uma
+// resposta válida e não é contabilizado como erro do upstream.
+func recordLookup(ctx context.Context, provider string, start time.Time, err error) error {
+	var upstreamErr *utils.UpstreamError
+	if errors.As(err, &upstreamErr) && upstreamErr.StatusCode == http.StatusNotFound {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		metrics.RecordUpstreamCall(ctx, provider, start, nil)
+		return ErrNotFound
+	}
+
+	metrics.RecordUpstreamCall(ctx, provider, start, err)
+	if err != nil {
+		return &utils.LookupError{Provider: provider, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package viacep
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviders_GetAddressByZipCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		statusCode  int
+		body        string
+		expectedErr error
+		expected    Address
+	}{
+		{
+			name:       "brasilapi",
+			provider:   "brasilapi",
+			statusCode: http.StatusOK,
+			body:       `{"cep":"01001000","state":"SP","city":"São Paulo","neighborhood":"Sé","street":"Praça da Sé","service":"viacep"}`,
+			expected: Address{
+				ZipCode:      "01001000",
+				Street:       "Praça da Sé",
+				Neighborhood: "Sé",
+				City:         "São Paulo",
+				State:        "SP",
+			},
+		},
+		{
+			name:        "brasilapi not found",
+			provider:    "brasilapi",
+			statusCode:  http.StatusNotFound,
+			body:        `{"name":"CepPromiseError","message":"Todos os serviços de CEP retornaram erro.","type":"service_error"}`,
+			expectedErr: ErrNotFound,
+		},
+		{
+			name:       "opencep",
+			provider:   "opencep",
+			statusCode: http.StatusOK,
+			body:       `{"cep":"01001-000","logradouro":"Praça da Sé","complemento":"lado ímpar","bairro":"Sé","localidade":"São Paulo","uf":"SP","ibge":"3550308"}`,
+			expected: Address{
+				ZipCode:      "01001-000",
+				Street:       "Praça da Sé",
+				Complement:   "lado ímpar",
+				Neighborhood: "Sé",
+				City:         "São Paulo",
+				State:        "SP",
+				IBGE:         "3550308",
+			},
+		},
+		{
+			name:        "opencep not found",
+			provider:    "opencep",
+			statusCode:  http.StatusNotFound,
+			body:        `{"error":"CEP não encontrado"}`,
+			expectedErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: &mockTransport{response: &http.Response{
+				StatusCode: tt.statusCode,
+				Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+			}}}
+			provider, err := NewProvider(tt.provider, client, time.Second)
+			assert.NoError(t, err)
+
+			address, err := provider.Service.GetAddressByZipCode(context.Background(), "01001000")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, address)
+			}
+		})
+	}
+}
@@ -1,24 +1,86 @@
 package viacep
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"net/http"
 )
 
+// Erro representa o campo "erro" do ViaCEP, que pode vir como booleano ou
+// como string ("true") dependendo da versão da API.
+type Erro bool
+
+func (e *Erro) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*e = Erro(v)
+	case string:
+		*e = Erro(v == "true")
+	default:
+		*e = false
+	}
+	return nil
+}
+
 type CepData struct {
-	Localidade string `json:"localidade"`
+	Cep         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	Uf          string `json:"uf"`
+	Ibge        string `json:"ibge"`
+	Ddd         string `json:"ddd"`
+	Erro        Erro   `json:"erro,omitempty"`
 }
 
 type ViaCEP struct {
 	CepData
 }
 
-func GetCityByZipCode(zipCode string) (string, error) {
+// Address é o endereço normalizado devolvido por todos os provedores de CEP.
+// As tags seguem os nomes usados pelo ViaCEP.
+type Address struct {
+	ZipCode      string `json:"cep"`
+	Street       string `json:"logradouro"`
+	Complement   string `json:"complemento,omitempty"`
+	Neighborhood string `json:"bairro"`
+	City         string `json:"localidade"`
+	State        string `json:"uf"`
+	IBGE         string `json:"ibge,omitempty"`
+	DDD          string `json:"ddd,omitempty"`
+}
+
+// Address converte o payload no endereço normalizado, devolvendo ErrNotFound
+// quando o ViaCEP sinaliza que o CEP não existe.
+func (c CepData) Address() (Address, error) {
+	if c.Erro || c.Localidade == "" {
+		return Address{}, ErrNotFound
+	}
+	return Address{
+		ZipCode:      c.Cep,
+		Street:       c.Logradouro,
+		Complement:   c.Complemento,
+		Neighborhood: c.Bairro,
+		City:         c.Localidade,
+		State:        c.Uf,
+		IBGE:         c.Ibge,
+		DDD:          c.Ddd,
+	}, nil
+}
+
+func GetAddressByZipCode(ctx context.Context, client *http.Client, zipCode string) (Address, error) {
 	url := "https://viacep.com.br/ws/" + zipCode + "/json/"
 	var data ViaCEP
-	err := utils.FetchData(url, &data)
+	err := utils.FetchDataWithContext(ctx, client, url, &data)
 	if err != nil {
-		return "", err
+		return Address{}, err
 	}
 
-	return data.Localidade, nil
+	return data.Address()
 }
@@ -1,15 +1,36 @@
 package viacep
 
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
 type ViaCEPInterface interface {
-	GetCityByZipCode(zipCode string) (string, error)
+	GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error)
 }
 
-type DefaultViaCEPService struct{}
+type DefaultViaCEPService struct {
+	Client  *http.Client
+	Timeout time.Duration
+}
 
-func NewViaCEPService() ViaCEPInterface {
-	return &DefaultViaCEPService{}
+func NewViaCEPService(client *http.Client, timeout time.Duration) ViaCEPInterface {
+	return &DefaultViaCEPService{
+		Client:  client,
+		Timeout: timeout,
+	}
 }
 
-func (s *DefaultViaCEPService) GetCityByZipCode(zipCode string) (string, error) {
-	return GetCityByZipCode(zipCode)
+func (s *DefaultViaCEPService) GetAddressByZipCode(ctx context.Context, zipCode string) (Address, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	address, err := GetAddressByZipCode(ctx, s.Client, zipCode)
+
+	return address, recordLookup(ctx, "viacep", start, err)
 }
@@ -2,11 +2,14 @@ package viacep
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,80 +22,121 @@ func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return m.response, m.err
 }
 
-func TestGetCityByZipCode(t *testing.T) {
-	// Guardar o cliente HTTP original
-	originalClient := http.DefaultClient
-	defer func() {
-		http.DefaultClient = originalClient
-	}()
-
+func TestGetAddressByZipCode(t *testing.T) {
 	tests := []struct {
-		name       string
-		zipCode    string
-		mockResp   *ViaCEP
-		statusCode int
-		expectErr  bool
-		expected   string
+		name        string
+		zipCode     string
+		body        string
+		statusCode  int
+		expectedErr error
+		expectErr   bool
+		expected    Address
 	}{
 		{
 			name:    "ValidZipCode",
-			zipCode: "12345678",
-			mockResp: &ViaCEP{
-				CepData: CepData{
-					Localidade: "São Paulo",
-				},
-			},
+			zipCode: "01001000",
+			body: `{"cep":"01001-000","logradouro":"Praça da Sé","complemento":"lado ímpar","bairro":"Sé",` +
+				`"localidade":"São Paulo","uf":"SP","ibge":"3550308","gia":"1004","ddd":"11","siafi":"7107"}`,
 			statusCode: http.StatusOK,
-			expectErr:  false,
-			expected:   "São Paulo",
+			expected: Address{
+				ZipCode:      "01001-000",
+				Street:       "Praça da Sé",
+				Complement:   "lado ímpar",
+				Neighborhood: "Sé",
+				City:         "São Paulo",
+				State:        "SP",
+				IBGE:         "3550308",
+				DDD:          "11",
+			},
 		},
 		{
-			name:       "InvalidZipCode",
-			zipCode:    "00000000",
-			mockResp:   nil,
-			statusCode: http.StatusNotFound,
-			expectErr:  true,
-			expected:   "",
+			name:        "ErroBoolean",
+			zipCode:     "99999999",
+			body:        `{"erro": true}`,
+			statusCode:  http.StatusOK,
+			expectedErr: ErrNotFound,
+		},
+		{
+			name:        "ErroString",
+			zipCode:     "99999999",
+			body:        `{"erro": "true"}`,
+			statusCode:  http.StatusOK,
+			expectedErr: ErrNotFound,
+		},
+		{
+			name:        "InvalidZipCode",
+			zipCode:     "00000000",
+			statusCode:  http.StatusNotFound,
+			expectedErr: ErrNotFound,
 		},
 		{
 			name:       "NetworkError",
 			zipCode:    "99999999",
-			mockResp:   nil,
 			statusCode: http.StatusInternalServerError,
 			expectErr:  true,
-			expected:   "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockTransport := &mockTransport{}
-
-			if tt.mockResp != nil {
-				jsonData, _ := json.Marshal(tt.mockResp)
-				mockTransport.response = &http.Response{
+			mockTransport := &mockTransport{
+				response: &http.Response{
 					StatusCode: tt.statusCode,
-					Body:       io.NopCloser(bytes.NewBuffer(jsonData)),
-				}
-			} else {
-				mockTransport.response = &http.Response{
-					StatusCode: tt.statusCode,
-					Body:       io.NopCloser(bytes.NewBufferString("")),
-				}
+					Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+				},
 			}
 
-			http.DefaultClient = &http.Client{
-				Transport: mockTransport,
-			}
+			service := NewViaCEPService(&http.Client{Transport: mockTransport}, time.Second)
 
-			city, err := GetCityByZipCode(tt.zipCode)
+			address, err := service.GetAddressByZipCode(context.Background(), tt.zipCode)
 
-			if tt.expectErr {
-				assert.Error(t, err)
-			} else {
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.expectErr:
+				var lookupErr *utils.LookupError
+				assert.ErrorAs(t, err, &lookupErr)
+				assert.Equal(t, "viacep", lookupErr.Provider)
+			default:
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, city)
+				assert.Equal(t, tt.expected, address)
 			}
 		})
 	}
 }
+
+func TestErro_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected Erro
+	}{
+		{body: `{"erro":true}`, expected: true},
+		{body: `{"erro":"true"}`, expected: true},
+		{body: `{"erro":false}`, expected: false},
+		{body: `{"erro":"false"}`, expected: false},
+		{body: `{}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var data CepData
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &data))
+			assert.Equal(t, tt.expected, data.Erro)
+		})
+	}
+}
+
+type slowTransport struct{}
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestGetAddressByZipCode_Timeout(t *testing.T) {
+	service := NewViaCEPService(&http.Client{Transport: &slowTransport{}}, 10*time.Millisecond)
+
+	_, err := service.GetAddressByZipCode(context.Background(), "12345678")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,74 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BreakerWeatherAPI deixa de chamar o provedor de clima enquanto o circuito
+// estiver aberto, devolvendo um erro que satisfaz errors.Is(err, breaker.ErrOpen).
+type BreakerWeatherAPI struct {
+	next    WeatherAPIInterface
+	breaker *breaker.Breaker
+}
+
+func NewBreakerWeatherAPI(next WeatherAPIInterface, b *breaker.Breaker) *BreakerWeatherAPI {
+	return &BreakerWeatherAPI{
+		next:    next,
+		breaker: b,
+	}
+}
+
+func (w *BreakerWeatherAPI) GetTempByCity(ctx context.Context, city string) (Response, error) {
+	span := trace.SpanFromContext(ctx)
+	done, err := w.breaker.Allow()
+	span.SetAttributes(attribute.String("circuit_breaker."+w.breaker.Name()+".state", w.breaker.State().String()))
+	if err != nil {
+		return Response{}, err
+	}
+
+	data, err := w.next.GetTempByCity(ctx, city)
+	done(breakerOutcome(err))
+
+	return data, err
+}
+
+// BreakerForecast protege as consultas de previsão com o circuit breaker do
+// provedor, como BreakerWeatherAPI faz com a temperatura atual.
+type BreakerForecast struct {
+	next    ForecastInterface
+	breaker *breaker.Breaker
+}
+
+func NewBreakerForecast(next ForecastInterface, b *breaker.Breaker) *BreakerForecast {
+	return &BreakerForecast{
+		next:    next,
+		breaker: b,
+	}
+}
+
+func (f *BreakerForecast) GetForecastByCity(ctx context.Context, city string, days int) (Forecast, error) {
+	span := trace.SpanFromContext(ctx)
+	done, err := f.breaker.Allow()
+	span.SetAttributes(attribute.String("circuit_breaker."+f.breaker.Name()+".state", f.breaker.State().String()))
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	data, err := f.next.GetForecastByCity(ctx, city, days)
+	done(breakerOutcome(err))
+
+	return data, err
+}
+
+// breakerOutcome trata a cidade inexistente como uma resposta válida do
+// provedor, para que consultas a cidades desconhecidas não abram o circuito.
+func breakerOutcome(err error) error {
+	if errors.Is(err, ErrCityNotFound) {
+		return nil
+	}
+	return err
+}
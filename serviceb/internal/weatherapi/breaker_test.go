@@ -0,0 +1,51 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/breaker"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerWeatherAPI_Outcomes(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedState breaker.State
+	}{
+		{
+			name:          "city not found keeps the breaker closed",
+			err:           &utils.LookupError{Provider: "openmeteo", Err: fmt.Errorf("%w: %s", ErrCityNotFound, "Atlantis")},
+			expectedState: breaker.StateClosed,
+		},
+		{
+			name:          "upstream failures open the breaker",
+			err:           &utils.LookupError{Provider: "openmeteo", Err: errors.New("connection refused")},
+			expectedState: breaker.StateOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &blockingWeatherAPI{release: make(chan struct{}), err: tt.err}
+			close(next.release)
+			b := breaker.New(breaker.Settings{
+				Name:        "openmeteo",
+				WindowSize:  4,
+				MinRequests: 4,
+				IsFailure:   utils.IsUpstreamFailure,
+			})
+			api := NewBreakerWeatherAPI(next, b)
+
+			for i := 0; i < 4; i++ {
+				_, err := api.GetTempByCity(context.Background(), "Atlantis")
+				assert.ErrorIs(t, err, tt.err)
+			}
+			assert.Equal(t, tt.expectedState, b.State())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package weatherapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/cache"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"time"
+)
+
+// CachedWeatherAPI agrupa as consultas simultâneas da mesma cidade em uma
+// única chamada ao upstream e, quando store não é nil e ttl é positivo,
+// guarda a temperatura de cada cidade por ttl.
+type CachedWeatherAPI struct {
+	next  WeatherAPIInterface
+	store cache.Store
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+func NewCachedWeatherAPI(next WeatherAPIInterface, store cache.Store, ttl time.Duration) *CachedWeatherAPI {
+	return &CachedWeatherAPI{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (c *CachedWeatherAPI) GetTempByCity(ctx context.Context, city string) (Response, error) {
+	span := trace.SpanFromContext(ctx)
+	key := "weather:" + strings.ToLower(city)
+
+	if c.cacheEnabled() {
+		if data, found := c.lookup(ctx, key); found {
+			return data, nil
+		}
+	}
+
+	// A chamada compartilhada não herda o cancelamento de quem a iniciou,
+	// para que a desistência de um cliente não derrube os demais.
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx := context.WithoutCancel(ctx)
+		data, err := c.next.GetTempByCity(fetchCtx, city)
+		if err != nil {
+			return data, err
+		}
+
+		if !c.cacheEnabled() {
+			return data, nil
+		}
+		if value, err := json.Marshal(data); err == nil {
+			if err := c.store.Set(fetchCtx, key, value, c.ttl); err != nil {
+				trace.SpanFromContext(fetchCtx).RecordError(err)
+			}
+		}
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	case result := <-ch:
+		span.SetAttributes(attribute.Bool("weather.coalesced", result.Shared))
+		if result.Err != nil {
+			return Response{}, result.Err
+		}
+		return result.Val.(Response), nil
+	}
+}
+
+func (c *CachedWeatherAPI) cacheEnabled() bool {
+	return c.store != nil && c.ttl > 0
+}
+
+func (c *CachedWeatherAPI) lookup(ctx context.Context, key string) (Response, bool) {
+	var data Response
+	value, found, err := c.store.Get(ctx, key)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+	if found && json.Unmarshal(value, &data) != nil {
+		found = false
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("weather.cache.hit", found))
+	metrics.RecordCacheLookup(ctx, "weather", found)
+	return data, found
+}
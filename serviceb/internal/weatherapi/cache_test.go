@@ -0,0 +1,118 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingWeatherAPI struct {
+	calls   atomic.Int32
+	release chan struct{}
+	resp    Response
+	err     error
+}
+
+func (b *blockingWeatherAPI) GetTempByCity(ctx context.Context, city string) (Response, error) {
+	b.calls.Add(1)
+	<-b.release
+	return b.resp, b.err
+}
+
+func TestCachedWeatherAPI_Coalescing(t *testing.T) {
+	next := &blockingWeatherAPI{release: make(chan struct{})}
+	next.resp.Temperature.TempC = 25
+	api := NewCachedWeatherAPI(next, cache.NewLRU(10), time.Minute)
+
+	var wg sync.WaitGroup
+	results := make(chan Response, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := api.GetTempByCity(context.Background(), "São Paulo")
+			assert.NoError(t, err)
+			results <- resp
+		}()
+	}
+
+	// Dá tempo para todas as goroutines ficarem aguardando a mesma chamada
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+	close(results)
+
+	for resp := range results {
+		assert.Equal(t, 25.0, resp.Temperature.TempC)
+	}
+	assert.Equal(t, int32(1), next.calls.Load())
+
+	// A próxima consulta, com outra grafia da cidade, vem do cache
+	resp, err := api.GetTempByCity(context.Background(), "SÃO PAULO")
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, resp.Temperature.TempC)
+	assert.Equal(t, int32(1), next.calls.Load())
+}
+
+func TestCachedWeatherAPI_CoalescingWithoutStore(t *testing.T) {
+	next := &blockingWeatherAPI{release: make(chan struct{})}
+	next.resp.Temperature.TempC = 25
+	api := NewCachedWeatherAPI(next, nil, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := api.GetTempByCity(context.Background(), "São Paulo")
+			assert.NoError(t, err)
+			assert.Equal(t, 25.0, resp.Temperature.TempC)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), next.calls.Load())
+
+	// Sem cache, a consulta seguinte volta ao upstream
+	_, err := api.GetTempByCity(context.Background(), "São Paulo")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), next.calls.Load())
+}
+
+func TestCachedWeatherAPI_DoesNotCacheErrors(t *testing.T) {
+	next := &blockingWeatherAPI{release: make(chan struct{}), err: errors.New("weather api error")}
+	close(next.release)
+	api := NewCachedWeatherAPI(next, cache.NewLRU(10), time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := api.GetTempByCity(context.Background(), "London")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), next.calls.Load())
+}
+
+func TestCachedWeatherAPI_CallerCancellation(t *testing.T) {
+	next := &blockingWeatherAPI{release: make(chan struct{})}
+	api := NewCachedWeatherAPI(next, cache.NewLRU(10), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := api.GetTempByCity(ctx, "London")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A chamada compartilhada segue e popula o cache
+	close(next.release)
+	assert.Eventually(t, func() bool {
+		_, found, _ := api.store.Get(context.Background(), "weather:london")
+		return found
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,59 @@
+package weatherapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// MaxForecastDays é o maior horizonte aceito pelo forecast.json da WeatherAPI.
+const MaxForecastDays = 14
+
+type ForecastInterface interface {
+	GetForecastByCity(ctx context.Context, city string, days int) (Forecast, error)
+}
+
+// ForecastDay traz a mínima, a máxima e a média de um dia da previsão.
+type ForecastDay struct {
+	Date string      `json:"date"`
+	Min  Temperature `json:"min"`
+	Max  Temperature `json:"max"`
+	Avg  Temperature `json:"avg"`
+}
+
+type Forecast struct {
+	Days     []ForecastDay `json:"days"`
+	Provider string        `json:"provider"`
+}
+
+type weatherAPIForecastResponse struct {
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC float64 `json:"maxtemp_c"`
+				MinTempC float64 `json:"mintemp_c"`
+				AvgTempC float64 `json:"avgtemp_c"`
+			} `json:"day"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+func (w *WeatherAPI) GetForecastByCity(ctx context.Context, city string, days int) (Forecast, error) {
+	wUrl := fmt.Sprintf("https://api.weatherapi.com/v1/forecast.json?key=%s&q=%s&days=%d&aqi=no&alerts=no", w.APIKey, url.QueryEscape(city), days)
+	var data weatherAPIForecastResponse
+	if err := w.fetch(ctx, city, wUrl, &data); err != nil {
+		return Forecast{}, err
+	}
+
+	forecast := Forecast{Days: []ForecastDay{}, Provider: "weatherapi"}
+	for _, day := range data.Forecast.ForecastDay {
+		forecast.Days = append(forecast.Days, ForecastDay{
+			Date: day.Date,
+			Min:  NewResponse(day.Day.MinTempC).Temperature,
+			Max:  NewResponse(day.Day.MaxTempC).Temperature,
+			Avg:  NewResponse(day.Day.AvgTempC).Temperature,
+		})
+	}
+	return forecast, nil
+}
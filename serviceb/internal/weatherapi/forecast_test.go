@@ -0,0 +1,77 @@
+package weatherapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeatherAPI_GetForecastByCity(t *testing.T) {
+	tests := []struct {
+		name     string
+		bodies   map[string]string
+		wantErr  bool
+		expected []ForecastDay
+	}{
+		{
+			name: "daily min, max and avg",
+			bodies: map[string]string{
+				"api.weatherapi.com": `{"forecast":{"forecastday":[
+					{"date":"2026-10-18","day":{"maxtemp_c":25,"mintemp_c":15,"avgtemp_c":20.2}},
+					{"date":"2026-10-19","day":{"maxtemp_c":30,"mintemp_c":18.5,"avgtemp_c":24}}
+				]}}`,
+			},
+			expected: []ForecastDay{
+				{
+					Date: "2026-10-18",
+					Min:  Temperature{TempC: 15, TempF: 59, TempK: 288.15},
+					Max:  Temperature{TempC: 25, TempF: 77, TempK: 298.15},
+					Avg:  Temperature{TempC: 20.2, TempF: 68.36, TempK: 293.35},
+				},
+				{
+					Date: "2026-10-19",
+					Min:  Temperature{TempC: 18.5, TempF: 65.3, TempK: 291.65},
+					Max:  Temperature{TempC: 30, TempF: 86, TempK: 303.15},
+					Avg:  Temperature{TempC: 24, TempF: 75.2, TempK: 297.15},
+				},
+			},
+		},
+		{
+			name:     "empty forecast",
+			bodies:   map[string]string{"api.weatherapi.com": `{"forecast":{"forecastday":[]}}`},
+			expected: []ForecastDay{},
+		},
+		{
+			name:    "upstream error",
+			bodies:  map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &routeTransport{bodies: tt.bodies}
+			api := NewWeatherAPI("test_api_key", &http.Client{Transport: transport}, time.Second)
+
+			forecast, err := api.GetForecastByCity(context.Background(), "São Paulo", 2)
+
+			assert.Len(t, transport.requests, 1)
+			assert.Equal(t, "/v1/forecast.json", transport.requests[0].URL.Path)
+			assert.Equal(t, "2", transport.requests[0].URL.Query().Get("days"))
+			assert.Equal(t, "São Paulo", transport.requests[0].URL.Query().Get("q"))
+			if tt.wantErr {
+				var lookupErr *utils.LookupError
+				assert.ErrorAs(t, err, &lookupErr)
+				assert.Equal(t, "weatherapi", lookupErr.Provider)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "weatherapi", forecast.Provider)
+			assert.Equal(t, tt.expected, forecast.Days)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ErrCityNotFound = errors.New("city not found")
+
+// OpenMeteo consulta a API gratuita do Open-Meteo, que trabalha com
+// coordenadas: a cidade é geocodificada antes da consulta da temperatura.
+type OpenMeteo struct {
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+type openMeteoGeocoding struct {
+	Results []struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"results"`
+}
+
+type openMeteoForecast struct {
+	Current struct {
+		Time        int64   `json:"time"`
+		Temperature float64 `json:"temperature_2m"`
+	} `json:"current"`
+}
+
+func NewOpenMeteo(client *http.Client, timeout time.Duration) *OpenMeteo {
+	return &OpenMeteo{
+		Client:  client,
+		Timeout: timeout,
+	}
+}
+
+func (o *OpenMeteo) GetTempByCity(ctx context.Context, city string) (Response, error) {
+	geoURL := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1&language=pt&countryCode=BR&format=json", url.QueryEscape(city))
+	var geo openMeteoGeocoding
+	if err := fetch(ctx, o.Client, o.Timeout, "openmeteo", geoURL, &geo); err != nil {
+		return Response{}, err
+	}
+	if len(geo.Results) == 0 {
+		return Response{}, &utils.LookupError{Provider: "openmeteo", Err: fmt.Errorf("%w: %s", ErrCityNotFound, city)}
+	}
+
+	return o.GetTempByCoordinates(ctx, geo.Results[0].Latitude, geo.Results[0].Longitude)
+}
+
+func (o *OpenMeteo) GetTempByCoordinates(ctx context.Context, latitude, longitude float64) (Response, error) {
+	forecastURL := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current=temperature_2m&timeformat=unixtime", latitude, longitude)
+	var forecast openMeteoForecast
+	if err := fetch(ctx, o.Client, o.Timeout, "openmeteo", forecastURL, &forecast); err != nil {
+		return Response{}, err
+	}
+
+	response := NewResponse(forecast.Current.Temperature)
+	response.ObservedAt = observedAt(forecast.Current.Time)
+	response.Provider = "openmeteo"
+	return response, nil
+}
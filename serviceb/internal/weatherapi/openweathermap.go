@@ -0,0 +1,43 @@
+package weatherapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type OpenWeatherMap struct {
+	APIKey  string
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+type openWeatherMapResponse struct {
+	Dt   int64 `json:"dt"`
+	Main struct {
+		Temp float64 `json:"temp"`
+	} `json:"main"`
+}
+
+func NewOpenWeatherMap(apiKey string, client *http.Client, timeout time.Duration) *OpenWeatherMap {
+	return &OpenWeatherMap{
+		APIKey:  apiKey,
+		Client:  client,
+		Timeout: timeout,
+	}
+}
+
+func (o *OpenWeatherMap) GetTempByCity(ctx context.Context, city string) (Response, error) {
+	oURL := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?q=%s,BR&units=metric&appid=%s", url.QueryEscape(city), o.APIKey)
+	var data openWeatherMapResponse
+	if err := fetch(ctx, o.Client, o.Timeout, "openweathermap", oURL, &data); err != nil {
+		return Response{}, err
+	}
+
+	response := NewResponse(data.Main.Temp)
+	response.ObservedAt = observedAt(data.Dt)
+	response.Provider = "openweathermap"
+	return response, nil
+}
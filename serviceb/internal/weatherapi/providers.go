@@ -0,0 +1,63 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/internal/metrics"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"net/http"
+	"time"
+)
+
+type ProviderConfig struct {
+	WeatherAPIKey        string
+	OpenWeatherMapAPIKey string
+	Client               *http.Client
+	Timeout              time.Duration
+}
+
+// NewProvider devolve a implementação de WeatherAPIInterface correspondente
+// a name: "weatherapi", "openmeteo" ou "openweathermap".
+func NewProvider(name string, cfg ProviderConfig) (WeatherAPIInterface, error) {
+	switch name {
+	case "weatherapi":
+		if cfg.WeatherAPIKey == "" {
+			return nil, fmt.Errorf("weatherapi provider requires an api key")
+		}
+		return NewWeatherAPI(cfg.WeatherAPIKey, cfg.Client, cfg.Timeout), nil
+	case "openmeteo":
+		return NewOpenMeteo(cfg.Client, cfg.Timeout), nil
+	case "openweathermap":
+		if cfg.OpenWeatherMapAPIKey == "" {
+			return nil, fmt.Errorf("openweathermap provider requires an api key")
+		}
+		return NewOpenWeatherMap(cfg.OpenWeatherMapAPIKey, cfg.Client, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", name)
+	}
+}
+
+// fetch consulta o provedor de clima, registrando a chamada nas métricas e
+// envolvendo a falha em utils.LookupError.
+func fetch(ctx context.Context, client *http.Client, timeout time.Duration, provider, url string, target interface{}) error {
+	start := time.Now()
+	err := utils.FetchWithTimeout(ctx, client, timeout, url, target)
+
+	return recordLookup(ctx, provider, start, err)
+}
+
+// recordLookup registra a chamada ao provedor e envolve a falha em
+// utils.LookupError. Uma cidade inexistente é uma resposta válida e não é
+// contabilizada como erro do upstream.
+func recordLookup(ctx context.Context, provider string, start time.Time, err error) error {
+	if errors.Is(err, ErrCityNotFound) {
+		metrics.RecordUpstreamCall(ctx, provider, start, nil)
+	} else {
+		metrics.RecordUpstreamCall(ctx, provider, start, err)
+	}
+	if err != nil {
+		return &utils.LookupError{Provider: provider, Err: err}
+	}
+	return nil
+}
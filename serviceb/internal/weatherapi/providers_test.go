@@ -0,0 +1,120 @@
+package weatherapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// routeTransport responde de acordo com o host da requisição
+type routeTransport struct {
+	bodies   map[string]string
+	requests []*http.Request
+}
+
+func (r *routeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req)
+	body, ok := r.bodies[req.URL.Host]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+func TestProviders_GetTempByCity(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		bodies       map[string]string
+		wantErr      bool
+		expected     Temperature
+		expectedHost string
+	}{
+		{
+			name:     "weatherapi",
+			provider: "weatherapi",
+			bodies: map[string]string{
+				"api.weatherapi.com": `{"current":{"last_updated_epoch":1760788800,"temp_c":20,"temp_f":68}}`,
+			},
+			expected:     Temperature{TempC: 20, TempF: 68, TempK: 293.15},
+			expectedHost: "api.weatherapi.com",
+		},
+		{
+			name:     "openmeteo",
+			provider: "openmeteo",
+			bodies: map[string]string{
+				"geocoding-api.open-meteo.com": `{"results":[{"latitude":-23.55,"longitude":-46.63}]}`,
+				"api.open-meteo.com":           `{"current":{"time":1760788800,"temperature_2m":25}}`,
+			},
+			expected:     Temperature{TempC: 25, TempF: 77, TempK: 298.15},
+			expectedHost: "api.open-meteo.com",
+		},
+		{
+			name:     "openmeteo unknown city",
+			provider: "openmeteo",
+			bodies: map[string]string{
+				"geocoding-api.open-meteo.com": `{}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:     "openweathermap",
+			provider: "openweathermap",
+			bodies: map[string]string{
+				"api.openweathermap.org": `{"dt":1760788800,"main":{"temp":30}}`,
+			},
+			expected:     Temperature{TempC: 30, TempF: 86, TempK: 303.15},
+			expectedHost: "api.openweathermap.org",
+		},
+		{
+			name:     "openweathermap error",
+			provider: "openweathermap",
+			bodies:   map[string]string{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &routeTransport{bodies: tt.bodies}
+			api, err := NewProvider(tt.provider, ProviderConfig{
+				WeatherAPIKey:        "key",
+				OpenWeatherMapAPIKey: "key",
+				Client:               &http.Client{Transport: transport},
+				Timeout:              time.Second,
+			})
+			assert.NoError(t, err)
+
+			resp, err := api.GetTempByCity(context.Background(), "São Paulo")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Temperature)
+			assert.Equal(t, tt.provider, resp.Provider)
+			assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC), resp.ObservedAt)
+			assert.Equal(t, tt.expectedHost, transport.requests[len(transport.requests)-1].URL.Host)
+		})
+	}
+}
+
+func TestNewProvider_Invalid(t *testing.T) {
+	_, err := NewProvider("accuweather", ProviderConfig{})
+	assert.Error(t, err)
+
+	_, err = NewProvider("weatherapi", ProviderConfig{})
+	assert.Error(t, err)
+
+	_, err = NewProvider("openweathermap", ProviderConfig{})
+	assert.Error(t, err)
+
+	_, err = NewProvider("openmeteo", ProviderConfig{})
+	assert.NoError(t, err)
+}
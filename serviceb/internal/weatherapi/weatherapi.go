@@ -1,39 +1,116 @@
 package weatherapi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/units"
 	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
+	"net/http"
 	"net/url"
+	"time"
 )
 
 type WeatherAPI struct {
-	APIKey string
+	APIKey  string
+	Client  *http.Client
+	Timeout time.Duration
 }
 
+type Temperature struct {
+	TempC float64 `json:"temp_c"`
+	TempF float64 `json:"temp_f"`
+	TempK float64 `json:"temp_k"`
+}
+
+// Response é a resposta normalizada devolvida por todos os provedores de
+// clima. A tag "current" coincide com o payload da WeatherAPI.
 type Response struct {
-	Temperature struct {
-		TempC float64 `json:"temp_c"`
-		TempF float64 `json:"temp_f"`
-		TempK float64 `json:"temp_k"`
+	Temperature Temperature `json:"current"`
+	ObservedAt  time.Time   `json:"observed_at"`
+	Provider    string      `json:"provider"`
+}
+
+type weatherAPIResponse struct {
+	Current struct {
+		TempC            float64 `json:"temp_c"`
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
 	} `json:"current"`
 }
 
-func NewWeatherAPI(apiKey string) *WeatherAPI {
+// NewResponse monta a resposta a partir da temperatura em Celsius. As demais
+// escalas são sempre calculadas aqui, mesmo quando o provedor as informa,
+// para que o arredondamento seja o mesmo em todos os provedores.
+func NewResponse(tempC float64) Response {
+	return Response{
+		Temperature: Temperature{
+			TempC: units.Round(tempC, units.Precision),
+			TempF: units.Convert(tempC, units.Celsius, units.Fahrenheit),
+			TempK: units.Convert(tempC, units.Celsius, units.Kelvin),
+		},
+	}
+}
+
+func NewWeatherAPI(apiKey string, client *http.Client, timeout time.Duration) *WeatherAPI {
 	return &WeatherAPI{
-		APIKey: apiKey,
+		APIKey:  apiKey,
+		Client:  client,
+		Timeout: timeout,
 	}
 }
 
-func (w *WeatherAPI) GetTempByCity(city string) (Response, error) {
+func (w *WeatherAPI) GetTempByCity(ctx context.Context, city string) (Response, error) {
 	wUrl := fmt.Sprintf("https://api.weatherapi.com/v1/current.json?key=%s&q=%s", w.APIKey, url.QueryEscape(city))
-	var data Response
+	var data weatherAPIResponse
+	if err := w.fetch(ctx, city, wUrl, &data); err != nil {
+		return Response{}, err
+	}
 
-	err := utils.FetchData(wUrl, &data)
-	if err != nil {
-		return data, err
+	response := NewResponse(data.Current.TempC)
+	response.ObservedAt = observedAt(data.Current.LastUpdatedEpoch)
+	response.Provider = "weatherapi"
+
+	return response, nil
+}
+
+// weatherAPINoMatchingLocation é o código de erro com que a WeatherAPI
+// responde 400 para uma cidade que ela não conhece.
+const weatherAPINoMatchingLocation = 1006
+
+type weatherAPIErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// fetch consulta a WeatherAPI, traduzindo a resposta de cidade inexistente em
+// ErrCityNotFound.
+func (w *WeatherAPI) fetch(ctx context.Context, city, wUrl string, target interface{}) error {
+	start := time.Now()
+	err := utils.FetchWithTimeout(ctx, w.Client, w.Timeout, wUrl, target)
+	if isNoMatchingLocation(err) {
+		err = fmt.Errorf("%w: %s", ErrCityNotFound, city)
 	}
 
-	data.Temperature.TempK = data.Temperature.TempC + 273
+	return recordLookup(ctx, "weatherapi", start, err)
+}
 
-	return data, nil
+func isNoMatchingLocation(err error) bool {
+	var upstreamErr *utils.UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	var body weatherAPIErrorResponse
+	return json.Unmarshal([]byte(upstreamErr.Body), &body) == nil && body.Error.Code == weatherAPINoMatchingLocation
+}
+
+// observedAt converte o horário da medição informado pelo provedor. Quando
+// ele não é informado, vale o horário da consulta.
+func observedAt(unix int64) time.Time {
+	if unix <= 0 {
+		return time.Now().UTC().Truncate(time.Second)
+	}
+	return time.Unix(unix, 0).UTC()
 }
@@ -1,5 +1,7 @@
 package weatherapi
 
+import "context"
+
 type WeatherAPIInterface interface {
-	GetTempByCity(city string) (Response, error)
+	GetTempByCity(ctx context.Context, city string) (Response, error)
 }
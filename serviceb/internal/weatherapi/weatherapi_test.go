@@ -2,12 +2,15 @@ package weatherapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/AndreD23/goexpert-labs-otel/serviceb/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +25,6 @@ func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 func TestWeatherAPI_GetTempByCity(t *testing.T) {
 	apiKey := "test_api_key"
-	api := NewWeatherAPI(apiKey)
 
 	tests := []struct {
 		name      string
@@ -46,7 +48,7 @@ func TestWeatherAPI_GetTempByCity(t *testing.T) {
 			wantErr:   false,
 			expectedC: 20,
 			expectedF: 68,
-			expectedK: 293,
+			expectedK: 293.15,
 		},
 		{
 			name: "city with special characters",
@@ -60,7 +62,21 @@ func TestWeatherAPI_GetTempByCity(t *testing.T) {
 			wantErr:   false,
 			expectedC: 25,
 			expectedF: 77,
-			expectedK: 298,
+			expectedK: 298.15,
+		},
+		{
+			name: "scales computed from celsius",
+			city: "Curitiba",
+			mockResp: &Response{Temperature: struct {
+				TempC float64 `json:"temp_c"`
+				TempF float64 `json:"temp_f"`
+				TempK float64 `json:"temp_k"`
+			}{TempC: 20.2, TempF: 68.4}},
+			mockErr:   nil,
+			wantErr:   false,
+			expectedC: 20.2,
+			expectedF: 68.36,
+			expectedK: 293.35,
 		},
 		{
 			name:      "API returns error",
@@ -74,9 +90,6 @@ func TestWeatherAPI_GetTempByCity(t *testing.T) {
 		},
 	}
 
-	// Guardar o cliente HTTP original
-	originalClient := http.DefaultClient
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Configurar o mock do transport
@@ -95,17 +108,9 @@ func TestWeatherAPI_GetTempByCity(t *testing.T) {
 				}
 			}
 
-			// Substituir o cliente HTTP padrão
-			http.DefaultClient = &http.Client{
-				Transport: mockTransport,
-			}
-
-			// Restaurar o cliente HTTP original após o teste
-			defer func() {
-				http.DefaultClient = originalClient
-			}()
+			api := NewWeatherAPI(apiKey, &http.Client{Transport: mockTransport}, time.Second)
 
-			resp, err := api.GetTempByCity(tt.city)
+			resp, err := api.GetTempByCity(context.Background(), tt.city)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -118,3 +123,64 @@ func TestWeatherAPI_GetTempByCity(t *testing.T) {
 		})
 	}
 }
+
+type statusTransport struct {
+	status int
+	body   string
+}
+
+func (s *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: s.status, Body: io.NopCloser(bytes.NewBufferString(s.body))}, nil
+}
+
+func TestWeatherAPI_UnknownCity(t *testing.T) {
+	lookups := map[string]func(api *WeatherAPI) error{
+		"current": func(api *WeatherAPI) error {
+			_, err := api.GetTempByCity(context.Background(), "Cidade Inexistente")
+			return err
+		},
+		"forecast": func(api *WeatherAPI) error {
+			_, err := api.GetForecastByCity(context.Background(), "Cidade Inexistente", 2)
+			return err
+		},
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantNotFound bool
+	}{
+		{
+			name:         "no matching location",
+			status:       http.StatusBadRequest,
+			body:         `{"error":{"code":1006,"message":"No matching location found."}}`,
+			wantNotFound: true,
+		},
+		{
+			name:   "other bad request",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":1003,"message":"Parameter q is missing."}}`,
+		},
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"code":1006,"message":"No matching location found."}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		for lookup, get := range lookups {
+			t.Run(tt.name+"/"+lookup, func(t *testing.T) {
+				api := NewWeatherAPI("test_api_key", &http.Client{Transport: &statusTransport{status: tt.status, body: tt.body}}, time.Second)
+
+				err := get(api)
+
+				var lookupErr *utils.LookupError
+				assert.ErrorAs(t, err, &lookupErr)
+				assert.Equal(t, "weatherapi", lookupErr.Provider)
+				assert.Equal(t, tt.wantNotFound, errors.Is(err, ErrCityNotFound))
+			})
+		}
+	}
+}
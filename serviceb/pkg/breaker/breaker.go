@@ -0,0 +1,226 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError é devolvido por Allow enquanto o circuito está aberto.
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is open", e.Name)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+type Settings struct {
+	Name string
+	// WindowSize é a quantidade de chamadas recentes usadas para calcular a
+	// taxa de falhas.
+	WindowSize int
+	// MinRequests é o mínimo de chamadas na janela antes que o circuito
+	// possa abrir.
+	MinRequests          int
+	FailureRateThreshold float64
+	// OpenTimeout é o tempo que o circuito fica aberto antes de liberar
+	// chamadas de teste (half-open).
+	OpenTimeout         time.Duration
+	HalfOpenMaxRequests int
+	// IsFailure decide quais erros contam como falha. Por padrão, qualquer
+	// erro que não seja o cancelamento da requisição pelo cliente.
+	IsFailure func(error) bool
+}
+
+type Breaker struct {
+	mu       sync.Mutex
+	settings Settings
+	state    State
+	window   []bool
+	next     int
+	count    int
+	failures int
+	openedAt time.Time
+	// generation muda a cada troca de estado, para que o resultado de uma
+	// chamada liberada em um ciclo anterior seja descartado.
+	generation uint64
+
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+
+	now func() time.Time
+}
+
+func New(settings Settings) *Breaker {
+	if settings.WindowSize <= 0 {
+		settings.WindowSize = 20
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = settings.WindowSize / 2
+	}
+	if settings.FailureRateThreshold <= 0 {
+		settings.FailureRateThreshold = 0.5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = defaultIsFailure
+	}
+
+	return &Breaker{
+		settings: settings,
+		window:   make([]bool, settings.WindowSize),
+		now:      time.Now,
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.settings.Name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// Allow verifica se uma chamada pode ser feita. Quando permitida, o chamador
+// deve invocar done com o resultado da chamada.
+func (b *Breaker) Allow() (done func(error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case StateOpen:
+		return nil, &OpenError{
+			Name:       b.settings.Name,
+			RetryAfter: b.openedAt.Add(b.settings.OpenTimeout).Sub(b.now()),
+		}
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxRequests {
+			return nil, &OpenError{Name: b.settings.Name}
+		}
+		b.halfOpenInFlight++
+	}
+
+	generation := b.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(generation, err) })
+	}, nil
+}
+
+// currentState promove o circuito de aberto para half-open quando o tempo
+// de espera já passou. Deve ser chamada com b.mu travado.
+func (b *Breaker) currentState() State {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		b.state = StateHalfOpen
+		b.generation++
+		b.halfOpenInFlight = 0
+		b.halfOpenSuccesses = 0
+	}
+	return b.state
+}
+
+func (b *Breaker) record(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	failed := err != nil && b.settings.IsFailure(err)
+
+	switch b.state {
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if failed {
+			b.open()
+			return
+		}
+		// Uma chamada de teste cancelada (cliente desconectado ou requisição
+		// paralela descartada) não diz nada sobre o upstream: só libera a vaga.
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.settings.HalfOpenMaxRequests {
+			b.close()
+		}
+	case StateClosed:
+		b.observe(failed)
+		if b.count >= b.settings.MinRequests &&
+			float64(b.failures)/float64(b.count) >= b.settings.FailureRateThreshold {
+			b.open()
+		}
+	}
+}
+
+func (b *Breaker) observe(failed bool) {
+	if b.count == len(b.window) {
+		if b.window[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+	b.window[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.window)
+}
+
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.generation++
+	b.openedAt = b.now()
+}
+
+func (b *Breaker) close() {
+	b.state = StateClosed
+	b.generation++
+	b.count = 0
+	b.failures = 0
+	b.next = 0
+}
+
+func defaultIsFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
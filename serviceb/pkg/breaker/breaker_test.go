@@ -0,0 +1,174 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBreaker(now *time.Time) *Breaker {
+	b := New(Settings{
+		Name:                 "weatherapi",
+		WindowSize:           4,
+		MinRequests:          4,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          10 * time.Second,
+		HalfOpenMaxRequests:  2,
+	})
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func call(t *testing.T, b *Breaker, err error) error {
+	t.Helper()
+	done, allowErr := b.Allow()
+	if allowErr != nil {
+		return allowErr
+	}
+	done(err)
+	return nil
+}
+
+func TestBreaker_OpensOnFailureRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("503 Service Unavailable")
+
+	assert.NoError(t, call(t, b, nil))
+	assert.NoError(t, call(t, b, failure))
+	assert.NoError(t, call(t, b, nil))
+	assert.Equal(t, StateClosed, b.State(), "abaixo de MinRequests")
+
+	assert.NoError(t, call(t, b, failure))
+	assert.Equal(t, StateOpen, b.State())
+
+	err := call(t, b, nil)
+	assert.ErrorIs(t, err, ErrOpen)
+	var openErr *OpenError
+	assert.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "weatherapi", openErr.Name)
+	assert.Equal(t, 10*time.Second, openErr.RetryAfter)
+}
+
+func TestBreaker_SlidingWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("timeout")
+
+	// Uma falha antiga sai da janela e não conta mais
+	for _, err := range []error{failure, nil, nil, nil, nil, failure} {
+		assert.NoError(t, call(t, b, err))
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("timeout")
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(t, b, failure))
+	}
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// Apenas HalfOpenMaxRequests chamadas de teste simultâneas
+	done1, err := b.Allow()
+	assert.NoError(t, err)
+	done2, err := b.Allow()
+	assert.NoError(t, err)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+
+	done1(nil)
+	done2(nil)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpenFailureReopens(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("timeout")
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(t, b, failure))
+	}
+	now = now.Add(10 * time.Second)
+
+	assert.NoError(t, call(t, b, failure))
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreaker_IgnoresCallsFromPreviousState(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("timeout")
+
+	// Chamada liberada com o circuito fechado que só termina no half-open
+	slowDone, err := b.Allow()
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(t, b, failure))
+	}
+	assert.Equal(t, StateOpen, b.State())
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	slowDone(nil)
+	assert.Equal(t, StateHalfOpen, b.State(), "não conta como chamada de teste")
+
+	// As duas chamadas de teste continuam disponíveis e decidem o estado
+	done1, err := b.Allow()
+	assert.NoError(t, err)
+	done2, err := b.Allow()
+	assert.NoError(t, err)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+
+	done1(nil)
+	assert.Equal(t, StateHalfOpen, b.State())
+	done2(nil)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_IgnoresCancellation(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(t, b, context.Canceled))
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpenCancellationReleasesSlot(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBreaker(&now)
+	failure := errors.New("timeout")
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(t, b, failure))
+	}
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// Chamadas de teste canceladas não fecham o circuito, mas liberam a vaga
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call(t, b, context.Canceled))
+	}
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	assert.NoError(t, call(t, b, nil))
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, call(t, b, nil))
+	assert.Equal(t, StateClosed, b.State())
+}
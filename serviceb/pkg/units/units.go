@@ -0,0 +1,91 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Precision é o número de casas decimais das temperaturas devolvidas. Com as
+// entradas em décimos de grau dos provedores, as conversões são exatas.
+const Precision = 2
+
+type Scale string
+
+const (
+	Celsius    Scale = "celsius"
+	Fahrenheit Scale = "fahrenheit"
+	Kelvin     Scale = "kelvin"
+)
+
+// Scales lista as escalas suportadas na ordem em que aparecem nas respostas.
+var Scales = []Scale{Celsius, Fahrenheit, Kelvin}
+
+var aliases = map[string]Scale{
+	"c":          Celsius,
+	"celsius":    Celsius,
+	"f":          Fahrenheit,
+	"fahrenheit": Fahrenheit,
+	"k":          Kelvin,
+	"kelvin":     Kelvin,
+}
+
+// Convert converte value da escala from para a escala to, arredondando o
+// resultado para Precision casas decimais.
+func Convert(value float64, from, to Scale) float64 {
+	var celsius float64
+	switch from {
+	case Fahrenheit:
+		celsius = (value - 32) * 5 / 9
+	case Kelvin:
+		celsius = value - 273.15
+	default:
+		celsius = value
+	}
+
+	var converted float64
+	switch to {
+	case Fahrenheit:
+		converted = celsius*9/5 + 32
+	case Kelvin:
+		converted = celsius + 273.15
+	default:
+		converted = celsius
+	}
+	return Round(converted, Precision)
+}
+
+// Round arredonda value para decimals casas, com empates para longe do zero.
+func Round(value float64, decimals int) float64 {
+	pow := math.Pow10(decimals)
+	scaled := value * pow
+	// Descarta o erro de representação binária (8702.499999999999 em vez de
+	// 8702.5) para que o empate seja reconhecido.
+	scaled = math.Round(scaled*1e6) / 1e6
+	return math.Round(scaled) / pow
+}
+
+// ParseScales interpreta uma lista separada por vírgulas de escalas, pelo
+// nome ("celsius") ou pela inicial ("c"). Uma lista vazia equivale a todas.
+func ParseScales(value string) ([]Scale, error) {
+	if strings.TrimSpace(value) == "" {
+		return Scales, nil
+	}
+
+	requested := make(map[Scale]bool)
+	for _, name := range strings.Split(value, ",") {
+		scale, ok := aliases[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown temperature scale %q", strings.TrimSpace(name))
+		}
+		requested[scale] = true
+	}
+
+	var scales []Scale
+	for _, scale := range Scales {
+		if requested[scale] {
+			scales = append(scales, scale)
+		}
+	}
+	return scales, nil
+}
@@ -0,0 +1,85 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		from     Scale
+		to       Scale
+		expected float64
+	}{
+		{name: "celsius to kelvin", value: 25, from: Celsius, to: Kelvin, expected: 298.15},
+		{name: "celsius to kelvin with decimals", value: 20.2, from: Celsius, to: Kelvin, expected: 293.35},
+		{name: "negative celsius to kelvin", value: -40, from: Celsius, to: Kelvin, expected: 233.15},
+		{name: "celsius to fahrenheit", value: 25, from: Celsius, to: Fahrenheit, expected: 77},
+		{name: "celsius to fahrenheit with decimals", value: 20.2, from: Celsius, to: Fahrenheit, expected: 68.36},
+		{name: "celsius to fahrenheit rounds half away from zero", value: 30.57, from: Celsius, to: Fahrenheit, expected: 87.03},
+		{name: "negative celsius to fahrenheit", value: -40, from: Celsius, to: Fahrenheit, expected: -40},
+		{name: "fahrenheit to celsius", value: 212, from: Fahrenheit, to: Celsius, expected: 100},
+		{name: "fahrenheit to kelvin", value: 32, from: Fahrenheit, to: Kelvin, expected: 273.15},
+		{name: "kelvin to celsius", value: 0, from: Kelvin, to: Celsius, expected: -273.15},
+		{name: "kelvin to fahrenheit", value: 300, from: Kelvin, to: Fahrenheit, expected: 80.33},
+		{name: "same scale", value: 21.456, from: Celsius, to: Celsius, expected: 21.46},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Convert(tt.value, tt.from, tt.to))
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		expected float64
+	}{
+		{value: 293.34999999999997, decimals: 2, expected: 293.35},
+		{value: 87.025, decimals: 2, expected: 87.03},
+		{value: -87.025, decimals: 2, expected: -87.03},
+		{value: 0.125, decimals: 2, expected: 0.13},
+		{value: 2.5, decimals: 0, expected: 3},
+		{value: 68.36, decimals: 1, expected: 68.4},
+		{value: 1.004, decimals: 2, expected: 1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Round(tt.value, tt.decimals), "Round(%v, %d)", tt.value, tt.decimals)
+	}
+}
+
+func TestParseScales(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []Scale
+		wantErr  bool
+	}{
+		{name: "empty means all", value: "", expected: []Scale{Celsius, Fahrenheit, Kelvin}},
+		{name: "single", value: "kelvin", expected: []Scale{Kelvin}},
+		{name: "initials", value: "k,c", expected: []Scale{Celsius, Kelvin}},
+		{name: "case and spaces", value: " Fahrenheit , C ", expected: []Scale{Celsius, Fahrenheit}},
+		{name: "duplicates", value: "c,celsius", expected: []Scale{Celsius}},
+		{name: "unknown", value: "c,rankine", wantErr: true},
+		{name: "empty item", value: "c,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scales, err := ParseScales(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, scales)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxBodyExcerpt limita quanto do corpo de uma resposta de erro é guardado.
+const maxBodyExcerpt = 512
+
+// UpstreamError descreve uma resposta não-200 de um upstream. A mensagem
+// mantém o formato "<status> <texto>" usado antes da introdução do tipo.
+type UpstreamError struct {
+	Upstream   string
+	StatusCode int
+	Body       string
+	Retryable  bool
+	RetryAfter time.Duration
+}
+
+func newUpstreamError(req *http.Request, resp *http.Response) *UpstreamError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyExcerpt))
+	retryAfter, _ := parseRetryAfter(resp, time.Now())
+
+	return &UpstreamError{
+		Upstream:   req.URL.Host,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Retryable:  retryableStatus(resp.StatusCode),
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// LookupError identifica o provedor, de CEP ou de clima, cuja consulta
+// falhou, permitindo que os handlers traduzam a falha sem depender da
+// mensagem do upstream.
+type LookupError struct {
+	Provider string
+	Err      error
+}
+
+func (e *LookupError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+// IsUpstreamFailure indica se err reflete um problema do upstream, e não da
+// requisição: respostas 4xx definitivas e o cancelamento pelo cliente não
+// contam. É usado como critério dos circuit breakers.
+func IsUpstreamFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) && upstreamErr.StatusCode < http.StatusInternalServerError {
+		return upstreamErr.Retryable
+	}
+	return true
+}
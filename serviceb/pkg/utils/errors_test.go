@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchDataWithContext_UpstreamError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    http.Header
+		body      string
+		expected  UpstreamError
+		errString string
+	}{
+		{
+			name:      "not found",
+			status:    http.StatusNotFound,
+			body:      `{"message":"CEP não encontrado"}`,
+			expected:  UpstreamError{Upstream: "example.com", StatusCode: http.StatusNotFound, Body: `{"message":"CEP não encontrado"}`},
+			errString: "404 Not Found",
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": []string{"7"}},
+			body:   `{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`,
+			expected: UpstreamError{
+				Upstream:   "example.com",
+				StatusCode: http.StatusTooManyRequests,
+				Body:       `{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`,
+				Retryable:  true,
+				RetryAfter: 7 * time.Second,
+			},
+			errString: "429 Too Many Requests",
+		},
+		{
+			name:      "body excerpt is truncated",
+			status:    http.StatusBadGateway,
+			body:      strings.Repeat("x", 2*maxBodyExcerpt),
+			expected:  UpstreamError{Upstream: "example.com", StatusCode: http.StatusBadGateway, Body: strings.Repeat("x", maxBodyExcerpt), Retryable: true},
+			errString: "502 Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: &mockTransport{mockResponse: &http.Response{
+				StatusCode: tt.status,
+				Header:     tt.header,
+				Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+			}}}
+
+			var data map[string]interface{}
+			err := FetchDataWithContext(context.Background(), client, "http://example.com/data", &data)
+
+			var upstreamErr *UpstreamError
+			assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &upstreamErr))
+			assert.Equal(t, tt.expected, *upstreamErr)
+			assert.EqualError(t, err, tt.errString)
+		})
+	}
+}
+
+func TestIsUpstreamFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "client canceled", err: context.Canceled, expected: false},
+		{name: "timeout", err: context.DeadlineExceeded, expected: true},
+		{name: "network error", err: errors.New("connection refused"), expected: true},
+		{name: "not found", err: &UpstreamError{StatusCode: http.StatusNotFound}, expected: false},
+		{name: "bad request", err: &UpstreamError{StatusCode: http.StatusBadRequest}, expected: false},
+		{name: "rate limited", err: &UpstreamError{StatusCode: http.StatusTooManyRequests, Retryable: true}, expected: true},
+		{name: "server error", err: &UpstreamError{StatusCode: http.StatusNotImplemented}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsUpstreamFailure(tt.err))
+		})
+	}
+}
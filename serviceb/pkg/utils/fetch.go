@@ -1,21 +1,153 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 )
 
+// sensitiveQueryParams são os parâmetros que levam as chaves dos provedores
+// (key da WeatherAPI, appid da OpenWeatherMap) e não podem ir para os spans.
+var sensitiveQueryParams = map[string]bool{
+	"key":          true,
+	"appid":        true,
+	"apikey":       true,
+	"api_key":      true,
+	"token":        true,
+	"access_token": true,
+}
+
+// NewHTTPClient devolve um *http.Client cujo transport cria um span de
+// cliente para cada requisição e injeta os headers de propagação do trace.
+// Se base for nil, http.DefaultTransport é utilizado. Os parâmetros com
+// chaves de API são mascarados na URL registrada no span.
+func NewHTTPClient(base http.RoundTripper) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{
+		Transport: otelhttp.NewTransport(
+			&redactingTransport{base: base},
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + r.URL.Host
+			}),
+		),
+	}
+}
+
+// redactingTransport fica entre o otelhttp e o transport real: o span de
+// cliente já está no contexto, e a URL que o otelhttp registrou é trocada
+// pela versão mascarada antes de a requisição sair.
+type redactingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *redactingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if span := trace.SpanFromContext(req.Context()); span.IsRecording() {
+		redacted := RedactURL(req.URL)
+		span.SetAttributes(attribute.String("http.url", redacted))
+		if os.Getenv("OTEL_SEMCONV_STABILITY_OPT_IN") == "http/dup" {
+			span.SetAttributes(attribute.String("url.full", redacted))
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// RedactURL devolve u com a senha e os valores dos parâmetros sensíveis
+// mascarados, para uso em spans, logs e mensagens de erro.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for param := range query {
+			if sensitiveQueryParams[strings.ToLower(param)] {
+				query.Set(param, "REDACTED")
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
+
+// withoutQuery devolve u sem a query string e sem senha, para os eventos
+// de span que não precisam dos parâmetros.
+func withoutQuery(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	stripped.ForceQuery = false
+	stripped.Fragment = ""
+	return stripped.Redacted()
+}
+
+// redactSecrets mascara em text os valores dos parâmetros sensíveis de u,
+// como os que aparecem na mensagem de um *url.Error.
+func redactSecrets(text string, u *url.URL) string {
+	for param, values := range u.Query() {
+		if !sensitiveQueryParams[strings.ToLower(param)] {
+			continue
+		}
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			text = strings.ReplaceAll(text, value, "REDACTED")
+			text = strings.ReplaceAll(text, url.QueryEscape(value), "REDACTED")
+		}
+	}
+	return text
+}
+
 func FetchData(url string, target interface{}) error {
-	resp, err := http.Get(url)
+	return FetchDataWithContext(context.Background(), http.DefaultClient, url, target)
+}
+
+// FetchWithTimeout é FetchDataWithContext limitada a timeout. Com timeout
+// zero ou negativo, vale apenas o prazo de ctx.
+func FetchWithTimeout(ctx context.Context, client *http.Client, timeout time.Duration, rawURL string, target interface{}) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return FetchDataWithContext(ctx, client, rawURL, target)
+}
+
+func FetchDataWithContext(ctx context.Context, client *http.Client, rawURL string, target interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
 	if err != nil {
+		// O *url.Error inclui a URL completa, com a chave, na mensagem
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = RedactURL(req.URL)
+		}
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return newUpstreamError(req, resp)
 	}
 
 	res, err := io.ReadAll(resp.Body)
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter é a fração (0 a 1) do backoff que é sorteada a cada tentativa,
+	// para que clientes diferentes não repitam as chamadas ao mesmo tempo.
+	Jitter float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff devolve a espera antes da tentativa attempt+1, já com jitter.
+func (p RetryPolicy) Backoff(attempt int, random float64) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff = backoff * (1 - p.Jitter + 2*p.Jitter*random)
+	}
+	return time.Duration(backoff)
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	random func() float64
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport repete requisições idempotentes que falharam por erro
+// de rede ou por status transitório (408, 429, 500, 502, 503 e 504),
+// respeitando o header Retry-After. Se o Retry-After pedir mais que
+// MaxBackoff, a resposta é devolvida sem nova tentativa. Cada tentativa é
+// registrada como evento no span do contexto da requisição, com a URL sem a
+// query string.
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+		random: rand.Float64,
+		sleep:  sleepContext,
+	}
+}
+
+// NewRetryingHTTPClient combina NewHTTPClient e NewRetryTransport, de forma
+// que cada tentativa gere o seu próprio span de cliente.
+func NewRetryingHTTPClient(base http.RoundTripper, policy RetryPolicy) *http.Client {
+	client := NewHTTPClient(base)
+	client.Transport = NewRetryTransport(client.Transport, policy)
+	return client
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	span := trace.SpanFromContext(ctx)
+
+	maxAttempts := t.policy.MaxAttempts
+	if maxAttempts < 1 || !isIdempotent(req) {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := cloneRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		attrs := []attribute.KeyValue{
+			attribute.Int("http.request.resend_count", attempt-1),
+			attribute.String("url.full", withoutQuery(req.URL)),
+		}
+		if err != nil {
+			attrs = append(attrs, attribute.String("error.message", redactSecrets(err.Error(), req.URL)))
+		} else {
+			attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+		}
+
+		retry := attempt < maxAttempts && ctx.Err() == nil && isRetryable(resp, err)
+		if !retry {
+			span.AddEvent("http.attempt", trace.WithAttributes(append(attrs, attribute.Bool("http.retry", false))...))
+			return resp, err
+		}
+
+		delay := t.policy.Backoff(attempt, t.random())
+		retryAfter, ok := parseRetryAfter(resp, time.Now())
+		if ok {
+			delay = retryAfter
+		}
+		// Um Retry-After acima de MaxBackoff prenderia o cliente por tempo
+		// indeterminado, e por isso encerra as tentativas
+		giveUp := ok && t.policy.MaxBackoff > 0 && retryAfter > t.policy.MaxBackoff
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < delay {
+			giveUp = true
+		}
+		if giveUp {
+			span.AddEvent("http.attempt", trace.WithAttributes(append(attrs, attribute.Bool("http.retry", false))...))
+			return resp, err
+		}
+
+		span.AddEvent("http.attempt", trace.WithAttributes(append(attrs,
+			attribute.Bool("http.retry", true),
+			attribute.String("http.retry.delay", delay.String()),
+		)...))
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter aceita os dois formatos do header: segundos ou data HTTP.
+func parseRetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
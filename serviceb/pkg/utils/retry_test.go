@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type scriptedTransport struct {
+	responses []*http.Response
+	errors    []error
+	calls     int
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := s.calls
+	s.calls++
+	if i < len(s.errors) && s.errors[i] != nil {
+		return nil, s.errors[i]
+	}
+	return s.responses[i], nil
+}
+
+func response(status int, headers ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"key":"value"}`))),
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+func TestRetryTransport(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		name           string
+		method         string
+		responses      []*http.Response
+		errors         []error
+		expectedStatus int
+		expectedCalls  int
+		expectedSleeps []time.Duration
+	}{
+		{
+			name:           "success on first attempt",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(http.StatusOK)},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name:           "retries 503 with exponential backoff",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(503), response(503), response(http.StatusOK)},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  3,
+			expectedSleeps: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:           "honors Retry-After",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(http.StatusTooManyRequests, "Retry-After", "1"), response(http.StatusOK)},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{time.Second},
+		},
+		{
+			name:           "gives up when Retry-After exceeds max backoff",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(http.StatusTooManyRequests, "Retry-After", "3600"), response(http.StatusOK)},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCalls:  1,
+		},
+		{
+			name:           "retries 500",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(http.StatusInternalServerError), response(http.StatusOK)},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{100 * time.Millisecond},
+		},
+		{
+			name:           "retries network errors",
+			method:         http.MethodGet,
+			responses:      []*http.Response{nil, response(http.StatusOK)},
+			errors:         []error{errors.New("connection reset by peer")},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{100 * time.Millisecond},
+		},
+		{
+			name:           "does not retry 404",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(http.StatusNotFound)},
+			expectedStatus: http.StatusNotFound,
+			expectedCalls:  1,
+		},
+		{
+			name:           "does not retry POST",
+			method:         http.MethodPost,
+			responses:      []*http.Response{response(503)},
+			expectedStatus: 503,
+			expectedCalls:  1,
+		},
+		{
+			name:           "gives up after max attempts",
+			method:         http.MethodGet,
+			responses:      []*http.Response{response(502), response(502), response(502)},
+			expectedStatus: 502,
+			expectedCalls:  3,
+			expectedSleeps: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &scriptedTransport{responses: tt.responses, errors: tt.errors}
+			var sleeps []time.Duration
+			transport := NewRetryTransport(base, policy).(*retryTransport)
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+
+			req, _ := http.NewRequest(tt.method, "http://example.com/data", nil)
+			resp, err := transport.RoundTrip(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedCalls, base.calls)
+			assert.Equal(t, tt.expectedSleeps, sleeps)
+		})
+	}
+}
+
+func TestRetryTransport_SpanEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "zipcode temperature")
+
+	base := &scriptedTransport{responses: []*http.Response{response(503), response(http.StatusOK)}}
+	transport := NewRetryTransport(base, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/data", nil)
+	_, err := transport.RoundTrip(req)
+	span.End()
+
+	assert.NoError(t, err)
+	events := recorder.Ended()[0].Events()
+	assert.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "http.attempt", event.Name)
+	}
+}
+
+func TestRetryTransport_SpanEventsRedactAPIKey(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "zipcode temperature")
+
+	rawURL := "https://api.weatherapi.com/v1/current.json?key=SUPERSECRET&q=Sao+Paulo"
+	base := &scriptedTransport{
+		responses: []*http.Response{nil, response(http.StatusOK)},
+		errors:    []error{&url.Error{Op: "Get", URL: rawURL, Err: errors.New("connection reset by peer")}},
+	}
+	transport := NewRetryTransport(base, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	_, err := transport.RoundTrip(req)
+	span.End()
+
+	assert.NoError(t, err)
+	events := recorder.Ended()[0].Events()
+	assert.Len(t, events, 2)
+	for _, event := range events {
+		assert.NotContains(t, fmt.Sprint(event.Attributes), "SUPERSECRET")
+		assert.Contains(t, event.Attributes, attribute.String("url.full", "https://api.weatherapi.com/v1/current.json"))
+	}
+}
+
+func TestRetryTransport_StopsWhenDeadlineTooClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	base := &scriptedTransport{responses: []*http.Response{response(http.StatusServiceUnavailable, "Retry-After", "30")}}
+	transport := NewRetryTransport(base, DefaultRetryPolicy())
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/data", nil)
+	resp, err := transport.RoundTrip(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, base.calls)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+
+	assert.Equal(t, 50*time.Millisecond, policy.Backoff(1, 0))
+	assert.Equal(t, 150*time.Millisecond, policy.Backoff(1, 1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2, 0.5))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(5, 0.5))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter(response(503, "Retry-After", "5"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	delay, ok = parseRetryAfter(response(503, "Retry-After", now.Add(10*time.Second).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, delay)
+
+	_, ok = parseRetryAfter(response(503, "Retry-After", strings.Repeat("x", 3)), now)
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter(response(503), now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Attributes são adicionados ao resource de todos os spans e métricas,
+	// por exemplo região, cluster ou versão do deploy.
+	Attributes map[string]string
+
+	TracesExporter  string
+	MetricsExporter string
+	ZipkinEndpoint  string
+	// OTLPEndpoint é a URL base do collector (http://otel-collector:4318) ou,
+	// como antes, apenas host:porta.
+	OTLPEndpoint string
+	// OTLPProtocol é grpc ou http/protobuf e vale para o exportador otlp.
+	OTLPProtocol   string
+	OTLPInsecure   bool
+	MetricInterval time.Duration
+
+	// Propagators é uma lista separada por vírgula de tracecontext, baggage,
+	// b3 (header único) e b3multi (headers X-B3-*).
+	Propagators string
+
+	Sampler string
+	// SamplerArg é a fração de traces novos amostrada pelos samplers
+	// *traceidratio e pela base do rules, ou o limite de traces por segundo
+	// do rate_limited. ConfigFromEnv usa defaultSamplerArg quando
+	// OTEL_TRACES_SAMPLER_ARG não está definida.
+	SamplerArg float64
+	// SlowThreshold é a latência a partir da qual o sampler "rules" mantém
+	// spans que não foram amostrados.
+	SlowThreshold time.Duration
+}
+
+// defaultSamplerArg é o valor de OTEL_TRACES_SAMPLER_ARG quando a variável
+// não está definida, 1.0 como na especificação do OpenTelemetry.
+const defaultSamplerArg = 1.0
+
+// ConfigFromEnv monta a configuração a partir das variáveis de ambiente
+// padrão do OpenTelemetry, usando serviceName quando OTEL_SERVICE_NAME não
+// estiver definida.
+func ConfigFromEnv(serviceName string) Config {
+	return Config{
+		ServiceName:     getEnv("OTEL_SERVICE_NAME", serviceName),
+		ServiceVersion:  getEnv("SERVICE_VERSION", ""),
+		Environment:     getEnv("DEPLOYMENT_ENVIRONMENT", ""),
+		TracesExporter:  getEnv("OTEL_TRACES_EXPORTER", ""),
+		MetricsExporter: getEnv("OTEL_METRICS_EXPORTER", ""),
+		ZipkinEndpoint:  getEnv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", ""),
+		OTLPEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTLPProtocol:    getEnv("OTEL_EXPORTER_OTLP_PROTOCOL", ""),
+		OTLPInsecure:    getEnv("OTEL_EXPORTER_OTLP_INSECURE", "true") == "true",
+		MetricInterval:  getEnvDuration("OTEL_METRIC_EXPORT_INTERVAL", 0),
+		Propagators:     getEnv("OTEL_PROPAGATORS", ""),
+		Sampler:         getEnv("OTEL_TRACES_SAMPLER", ""),
+		SamplerArg:      getEnvFloat("OTEL_TRACES_SAMPLER_ARG", defaultSamplerArg),
+		SlowThreshold:   getEnvDuration("OTEL_TRACES_SLOW_THRESHOLD", 0),
+	}
+}
+
+func (c Config) withDefaults() Config {
+	if c.TracesExporter == "" {
+		c.TracesExporter = "zipkin"
+	}
+	if c.MetricsExporter == "" {
+		c.MetricsExporter = "prometheus"
+	}
+	if c.ZipkinEndpoint == "" {
+		c.ZipkinEndpoint = "http://zipkin:9411/api/v2/spans"
+	}
+	if c.OTLPProtocol == "" {
+		c.OTLPProtocol = "http/protobuf"
+	}
+	if c.MetricInterval <= 0 {
+		c.MetricInterval = 15 * time.Second
+	}
+	if c.Propagators == "" {
+		c.Propagators = "tracecontext,baggage"
+	}
+	if c.Sampler == "" {
+		c.Sampler = "parentbased_always_on"
+	}
+	if c.Sampler == "rules" && c.SlowThreshold <= 0 {
+		c.SlowThreshold = time.Second
+	}
+	return c
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
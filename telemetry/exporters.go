@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"net/url"
+	"strings"
+)
+
+func newTraceExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracesExporter {
+	case "none":
+		return nil, nil
+	case "zipkin":
+		return zipkin.New(cfg.ZipkinEndpoint)
+	case "otlp", "otlp-grpc", "otlp-http":
+		protocol, err := otlpProtocol(cfg, cfg.TracesExporter)
+		if err != nil {
+			return nil, err
+		}
+		if protocol == "grpc" {
+			opts := []otlptracegrpc.Option{}
+			if endpointURL, ok := otlpEndpointURL(cfg.OTLPEndpoint, ""); ok {
+				opts = append(opts, otlptracegrpc.WithEndpointURL(endpointURL))
+			} else {
+				if cfg.OTLPEndpoint != "" {
+					opts = append(opts, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+				}
+				if cfg.OTLPInsecure {
+					opts = append(opts, otlptracegrpc.WithInsecure())
+				}
+			}
+			return otlptracegrpc.New(ctx, opts...)
+		}
+		opts := []otlptracehttp.Option{}
+		if endpointURL, ok := otlpEndpointURL(cfg.OTLPEndpoint, "/v1/traces"); ok {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpointURL))
+		} else {
+			if cfg.OTLPEndpoint != "" {
+				opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+			}
+			if cfg.OTLPInsecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", cfg.TracesExporter)
+	}
+}
+
+func newMetricReaders(ctx context.Context, cfg Config) ([]sdkmetric.Reader, error) {
+	var readers []sdkmetric.Reader
+	for _, name := range strings.Split(cfg.MetricsExporter, ",") {
+		reader, err := newMetricReader(ctx, cfg, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if reader != nil {
+			readers = append(readers, reader)
+		}
+	}
+	return readers, nil
+}
+
+func newMetricReader(ctx context.Context, cfg Config, name string) (sdkmetric.Reader, error) {
+	var exporter sdkmetric.Exporter
+	var err error
+
+	switch name {
+	case "none", "":
+		return nil, nil
+	case "prometheus":
+		return prometheus.New(prometheus.WithRegisterer(newPrometheusRegistry()))
+	case "otlp", "otlp-grpc", "otlp-http":
+		protocol, err := otlpProtocol(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		if protocol == "grpc" {
+			opts := []otlpmetricgrpc.Option{}
+			if endpointURL, ok := otlpEndpointURL(cfg.OTLPEndpoint, ""); ok {
+				opts = append(opts, otlpmetricgrpc.WithEndpointURL(endpointURL))
+			} else {
+				if cfg.OTLPEndpoint != "" {
+					opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint))
+				}
+				if cfg.OTLPInsecure {
+					opts = append(opts, otlpmetricgrpc.WithInsecure())
+				}
+			}
+			exporter, err = otlpmetricgrpc.New(ctx, opts...)
+			break
+		}
+		opts := []otlpmetrichttp.Option{}
+		if endpointURL, ok := otlpEndpointURL(cfg.OTLPEndpoint, "/v1/metrics"); ok {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(endpointURL))
+		} else {
+			if cfg.OTLPEndpoint != "" {
+				opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint))
+			}
+			if cfg.OTLPInsecure {
+				opts = append(opts, otlpmetrichttp.WithInsecure())
+			}
+		}
+		exporter, err = otlpmetrichttp.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdoutmetric.New()
+	default:
+		return nil, fmt.Errorf("unknown metrics exporter %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(cfg.MetricInterval)), nil
+}
+
+// otlpProtocol devolve o protocolo do exportador OTLP. Os nomes otlp-grpc e
+// otlp-http, anteriores a OTEL_EXPORTER_OTLP_PROTOCOL, continuam aceitos.
+func otlpProtocol(cfg Config, exporter string) (string, error) {
+	switch exporter {
+	case "otlp-grpc":
+		return "grpc", nil
+	case "otlp-http":
+		return "http/protobuf", nil
+	}
+	switch cfg.OTLPProtocol {
+	case "grpc", "http/protobuf":
+		return cfg.OTLPProtocol, nil
+	default:
+		return "", fmt.Errorf("unsupported OTLP protocol %q", cfg.OTLPProtocol)
+	}
+}
+
+// otlpEndpointURL interpreta OTEL_EXPORTER_OTLP_ENDPOINT como a URL base do
+// collector, acrescentando o caminho do sinal, como pede a especificação.
+// Com uma URL, o esquema define se a conexão usa TLS; um host:porta sem
+// esquema é repassado ao exportador como está.
+func otlpEndpointURL(endpoint, signalPath string) (string, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + signalPath
+	return u.String(), true
+}
@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	originalMP := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(originalMP)
+
+	r := chi.NewRouter()
+	r.Use(HTTPMetrics())
+	r.Get("/{zipCode}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid zipcode", http.StatusUnprocessableEntity)
+	})
+
+	for _, path := range []string{"/123", "/456"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	count := findMetric(t, rm, "http.server.request.count").Data.(metricdata.Sum[int64])
+	assert.Len(t, count.DataPoints, 1)
+	point := count.DataPoints[0]
+	assert.Equal(t, int64(2), point.Value)
+
+	route, _ := point.Attributes.Value(attribute.Key("http.route"))
+	assert.Equal(t, "/{zipCode}", route.AsString())
+	status, _ := point.Attributes.Value(attribute.Key("http.response.status_code"))
+	assert.Equal(t, int64(http.StatusUnprocessableEntity), status.AsInt64())
+
+	duration := findMetric(t, rm, "http.server.request.duration").Data.(metricdata.Histogram[float64])
+	assert.Equal(t, uint64(2), duration.DataPoints[0].Count)
+}
+
+func TestMetricsHandler_Prometheus(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName:     "test",
+		TracesExporter:  "none",
+		MetricsExporter: "prometheus",
+	})
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	counter, _ := otel.Meter("test").Int64Counter("zipcode.validation.failures")
+	counter.Add(context.Background(), 1)
+
+	w := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "zipcode_validation_failures_total")
+}
+
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Metrics{}
+}
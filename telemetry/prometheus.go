@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync/atomic"
+)
+
+var metricsHandler atomic.Pointer[http.Handler]
+
+// newPrometheusRegistry cria o registry usado pelo exportador Prometheus e
+// o publica no handler devolvido por MetricsHandler.
+func newPrometheusRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	metricsHandler.Store(&handler)
+
+	return registry
+}
+
+// MetricsHandler expõe as métricas no formato Prometheus. Responde 404
+// enquanto o exportador "prometheus" não estiver habilitado.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := metricsHandler.Load()
+		if handler == nil {
+			http.NotFound(w, r)
+			return
+		}
+		(*handler).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"sort"
+	"strings"
+)
+
+func newPropagator(cfg Config) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range strings.Split(cfg.Propagators, ",") {
+		switch strings.TrimSpace(name) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "none", "":
+		default:
+			return nil, fmt.Errorf("unknown propagator %q", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// BaggageAttributes converte as entradas do baggage presente em ctx em
+// atributos "baggage.<chave>", para que valores como tenant e client id
+// fiquem visíveis nos spans.
+func BaggageAttributes(ctx context.Context) []attribute.KeyValue {
+	members := baggage.FromContext(ctx).Members()
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Key() < members[j].Key()
+	})
+
+	attrs := make([]attribute.KeyValue, 0, len(members))
+	for _, member := range members {
+		attrs = append(attrs, attribute.String("baggage."+member.Key(), member.Value()))
+	}
+	return attrs
+}
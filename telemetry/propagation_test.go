@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator_Extract(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{
+			name:    "tracecontext",
+			headers: map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		},
+		{
+			name:    "b3 single header",
+			headers: map[string]string{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+		},
+		{
+			name: "b3 multi header",
+			headers: map[string]string{
+				"X-B3-TraceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+				"X-B3-Sampled": "1",
+			},
+		},
+	}
+
+	propagator, err := newPropagator(Config{Propagators: "tracecontext,baggage,b3"})
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+
+			ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(header))
+			sc := trace.SpanContextFromContext(ctx)
+
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID().String())
+			assert.True(t, sc.IsSampled())
+		})
+	}
+}
+
+func TestNewPropagator_Inject(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x00, 0xf0},
+		TraceFlags: trace.FlagsSampled,
+	})
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(trace.ContextWithSpanContext(context.Background(), sc), bag)
+
+	tests := []struct {
+		propagators string
+		present     []string
+		absent      []string
+	}{
+		{propagators: "tracecontext,baggage", present: []string{"traceparent", "baggage"}, absent: []string{"b3", "X-B3-TraceId"}},
+		{propagators: "b3", present: []string{"b3"}, absent: []string{"traceparent", "X-B3-TraceId"}},
+		{propagators: "b3multi", present: []string{"X-B3-TraceId", "X-B3-SpanId"}, absent: []string{"b3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.propagators, func(t *testing.T) {
+			propagator, err := newPropagator(Config{Propagators: tt.propagators})
+			assert.NoError(t, err)
+
+			header := http.Header{}
+			propagator.Inject(ctx, propagation.HeaderCarrier(header))
+
+			for _, key := range tt.present {
+				assert.NotEmpty(t, header.Get(key), key)
+			}
+			for _, key := range tt.absent {
+				assert.Empty(t, header.Get(key), key)
+			}
+		})
+	}
+}
+
+func TestNewPropagator_Unknown(t *testing.T) {
+	_, err := newPropagator(Config{Propagators: "tracecontext,jaeger"})
+	assert.Error(t, err)
+}
+
+func TestBaggageAttributes(t *testing.T) {
+	tenant, _ := baggage.NewMember("tenant", "acme")
+	clientID, _ := baggage.NewMember("client_id", "42")
+	bag, _ := baggage.New(tenant, clientID)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("baggage.client_id", "42"),
+		attribute.String("baggage.tenant", "acme"),
+	}, BaggageAttributes(ctx))
+	assert.Empty(t, BaggageAttributes(context.Background()))
+}
@@ -0,0 +1,254 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
+)
+
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	switch cfg.Sampler {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(cfg.SamplerArg), nil
+	case "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplerArg)), nil
+	case "rate_limited":
+		return sdktrace.ParentBased(NewRateLimitingSampler(cfg.SamplerArg)), nil
+	case "rules":
+		return NewRuleBasedSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplerArg))), nil
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", cfg.Sampler)
+	}
+}
+
+// rateLimitingSampler amostra no máximo tracesPerSecond traces por segundo
+// usando um token bucket com capacidade de um segundo de tráfego.
+type rateLimitingSampler struct {
+	mu              sync.Mutex
+	tracesPerSecond float64
+	maxBalance      float64
+	balance         float64
+	lastTick        time.Time
+	now             func() time.Time
+}
+
+func NewRateLimitingSampler(tracesPerSecond float64) sdktrace.Sampler {
+	return newRateLimitingSampler(tracesPerSecond, time.Now)
+}
+
+func newRateLimitingSampler(tracesPerSecond float64, now func() time.Time) *rateLimitingSampler {
+	maxBalance := tracesPerSecond
+	if maxBalance < 1 {
+		maxBalance = 1
+	}
+	return &rateLimitingSampler{
+		tracesPerSecond: tracesPerSecond,
+		maxBalance:      maxBalance,
+		balance:         maxBalance,
+		lastTick:        now(),
+		now:             now,
+	}
+}
+
+func (s *rateLimitingSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	decision := sdktrace.Drop
+	if s.tryAcquire() {
+		decision = sdktrace.RecordAndSample
+	}
+	return sdktrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+func (s *rateLimitingSampler) tryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.balance += now.Sub(s.lastTick).Seconds() * s.tracesPerSecond
+	if s.balance > s.maxBalance {
+		s.balance = s.maxBalance
+	}
+	s.lastTick = now
+
+	if s.balance < 1 {
+		return false
+	}
+	s.balance--
+	return true
+}
+
+func (s *rateLimitingSampler) Description() string {
+	return fmt.Sprintf("RateLimitingSampler{%g}", s.tracesPerSecond)
+}
+
+// ruleBasedSampler delega a decisão ao sampler base, mas mantém gravados os
+// spans que ele descartaria para que o ruleBasedProcessor possa exportar os
+// traces cuja raiz terminar com erro ou acima do limite de latência. Como a
+// decisão só sai no fim da requisição, o serviço chamado recebe o trace como
+// não amostrado e aplica as próprias regras à sua parte.
+type ruleBasedSampler struct {
+	base sdktrace.Sampler
+}
+
+func NewRuleBasedSampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return &ruleBasedSampler{base: base}
+}
+
+func (s *ruleBasedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.base.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s *ruleBasedSampler) Description() string {
+	return fmt.Sprintf("RuleBasedSampler{%s}", s.base.Description())
+}
+
+const (
+	// maxPendingTraces e maxPendingSpans limitam a memória usada pelos
+	// traces não amostrados enquanto a raiz local não termina.
+	maxPendingTraces = 4096
+	maxPendingSpans  = 512
+)
+
+// ruleBasedProcessor repassa ao próximo processor os spans amostrados. Os não
+// amostrados ficam guardados por trace até o span raiz local (o primeiro do
+// processo, sem pai ou com pai remoto) terminar: se ele terminou com erro ou
+// foi lento, o trace inteiro é exportado, com os filhos e as chamadas aos
+// upstreams; caso contrário, é descartado. Spans que terminam depois da
+// raiz, ou traces acima de maxPendingTraces, são descartados.
+type ruleBasedProcessor struct {
+	next          sdktrace.SpanProcessor
+	slowThreshold time.Duration
+
+	mu      sync.Mutex
+	pending map[trace.TraceID]*pendingTrace
+}
+
+type pendingTrace struct {
+	openRoots int
+	keep      bool
+	spans     []sdktrace.ReadOnlySpan
+}
+
+func NewRuleBasedProcessor(next sdktrace.SpanProcessor, slowThreshold time.Duration) sdktrace.SpanProcessor {
+	return &ruleBasedProcessor{
+		next:          next,
+		slowThreshold: slowThreshold,
+		pending:       make(map[trace.TraceID]*pendingTrace),
+	}
+}
+
+func (p *ruleBasedProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+	if s.SpanContext().IsSampled() || !isLocalRoot(s) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	traceID := s.SpanContext().TraceID()
+	pending, ok := p.pending[traceID]
+	if !ok {
+		if len(p.pending) >= maxPendingTraces {
+			return
+		}
+		pending = &pendingTrace{}
+		p.pending[traceID] = pending
+	}
+	pending.openRoots++
+}
+
+func (p *ruleBasedProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+	for _, span := range p.collect(s) {
+		p.next.OnEnd(sampledSpan{ReadOnlySpan: span})
+	}
+}
+
+// collect guarda s no trace pendente e, quando s é a última raiz local do
+// trace a terminar, devolve os spans a exportar, se o trace deve ser mantido.
+func (p *ruleBasedProcessor) collect(s sdktrace.ReadOnlySpan) []sdktrace.ReadOnlySpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	traceID := s.SpanContext().TraceID()
+	pending, ok := p.pending[traceID]
+	if !ok {
+		return nil
+	}
+	root := isLocalRoot(s)
+	if root || len(pending.spans) < maxPendingSpans {
+		pending.spans = append(pending.spans, s)
+	}
+	if !root {
+		return nil
+	}
+
+	pending.keep = pending.keep || p.keep(s)
+	pending.openRoots--
+	if pending.openRoots > 0 {
+		return nil
+	}
+	delete(p.pending, traceID)
+	if !pending.keep {
+		return nil
+	}
+	return pending.spans
+}
+
+func (p *ruleBasedProcessor) keep(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+	for _, attr := range s.Attributes() {
+		if attr.Key == attribute.Key("http.response.status_code") && attr.Value.AsInt64() >= 500 {
+			return true
+		}
+	}
+	return p.slowThreshold > 0 && s.EndTime().Sub(s.StartTime()) >= p.slowThreshold
+}
+
+func (p *ruleBasedProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *ruleBasedProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func isLocalRoot(s sdktrace.ReadOnlySpan) bool {
+	parent := s.Parent()
+	return !parent.IsValid() || parent.IsRemote()
+}
+
+// sampledSpan marca como amostrado um span que o sampler apenas gravou, já
+// que os exporters ignoram spans sem a flag de amostragem.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
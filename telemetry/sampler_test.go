@@ -0,0 +1,272 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func rootParams() sdktrace.SamplingParameters {
+	return sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		Name:          "zipcode temperature",
+	}
+}
+
+func TestNewSampler(t *testing.T) {
+	sampledParent := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		parent   context.Context
+		expected sdktrace.SamplingDecision
+	}{
+		{
+			name:     "always on",
+			cfg:      Config{Sampler: "always_on"},
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			name:     "ratio zero drops root",
+			cfg:      Config{Sampler: "parentbased_traceidratio", SamplerArg: 0},
+			expected: sdktrace.Drop,
+		},
+		{
+			name:     "ratio zero follows sampled parent",
+			cfg:      Config{Sampler: "parentbased_traceidratio", SamplerArg: 0},
+			parent:   sampledParent,
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			name:     "ratio one samples root",
+			cfg:      Config{Sampler: "parentbased_traceidratio", SamplerArg: 1},
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			name:     "rules records instead of dropping",
+			cfg:      Config{Sampler: "rules", SamplerArg: 0},
+			expected: sdktrace.RecordOnly,
+		},
+		{
+			name:     "rate limited samples first root",
+			cfg:      Config{Sampler: "rate_limited", SamplerArg: 10},
+			expected: sdktrace.RecordAndSample,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler, err := newSampler(tt.cfg)
+			assert.NoError(t, err)
+
+			params := rootParams()
+			if tt.parent != nil {
+				params.ParentContext = tt.parent
+			}
+			assert.Equal(t, tt.expected, sampler.ShouldSample(params).Decision)
+		})
+	}
+}
+
+func TestNewSampler_RulesFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		expected sdktrace.SamplingDecision
+	}{
+		{
+			name:     "default base ratio samples root",
+			arg:      "",
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			name:     "base ratio zero records instead of dropping",
+			arg:      "0",
+			expected: sdktrace.RecordOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", "rules")
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.arg)
+
+			cfg := ConfigFromEnv("serviceb").withDefaults()
+			assert.Equal(t, time.Second, cfg.SlowThreshold)
+
+			sampler, err := newSampler(cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sampler.ShouldSample(rootParams()).Decision)
+		})
+	}
+}
+
+func TestRateLimitingSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	sampler := newRateLimitingSampler(2, func() time.Time { return now })
+
+	decisions := func(n int) []sdktrace.SamplingDecision {
+		var got []sdktrace.SamplingDecision
+		for i := 0; i < n; i++ {
+			got = append(got, sampler.ShouldSample(rootParams()).Decision)
+		}
+		return got
+	}
+
+	assert.Equal(t, []sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.RecordAndSample, sdktrace.Drop}, decisions(3))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, []sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.Drop}, decisions(2))
+
+	// O saldo nunca passa de um segundo de tráfego
+	now = now.Add(time.Minute)
+	assert.Equal(t, []sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.RecordAndSample, sdktrace.Drop}, decisions(3))
+}
+
+func TestRateLimitingSampler_FractionalRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	sampler := newRateLimitingSampler(0.5, func() time.Time { return now })
+
+	assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(rootParams()).Decision)
+	assert.Equal(t, sdktrace.Drop, sampler.ShouldSample(rootParams()).Decision)
+
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(rootParams()).Decision)
+}
+
+func TestRuleBasedSampling(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewRuleBasedSampler(sdktrace.NeverSample())),
+		sdktrace.WithSpanProcessor(NewRuleBasedProcessor(recorder, 500*time.Millisecond)),
+	)
+	tracer := tp.Tracer("test")
+	start := time.Now()
+
+	_, fast := tracer.Start(context.Background(), "fast", trace.WithTimestamp(start))
+	fast.End(trace.WithTimestamp(start.Add(10 * time.Millisecond)))
+
+	_, failed := tracer.Start(context.Background(), "failed", trace.WithTimestamp(start))
+	failed.SetStatus(codes.Error, "weather lookup failed")
+	failed.End(trace.WithTimestamp(start.Add(10 * time.Millisecond)))
+
+	_, slow := tracer.Start(context.Background(), "slow", trace.WithTimestamp(start))
+	slow.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	var names []string
+	for _, s := range recorder.Ended() {
+		assert.True(t, s.SpanContext().IsSampled())
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"failed", "slow"}, names)
+}
+
+func TestRuleBasedSampling_WholeTrace(t *testing.T) {
+	remoteParent := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{2},
+		SpanID:  trace.SpanID{2},
+		Remote:  true,
+	}))
+
+	tests := []struct {
+		name       string
+		parent     context.Context
+		rootStatus codes.Code
+		childError bool
+		duration   time.Duration
+		expected   []string
+	}{
+		{
+			name:     "fast successful trace is dropped",
+			parent:   context.Background(),
+			duration: 10 * time.Millisecond,
+		},
+		{
+			name:       "failed root keeps children",
+			parent:     context.Background(),
+			rootStatus: codes.Error,
+			duration:   10 * time.Millisecond,
+			expected:   []string{"GET viacep.com.br", "GET api.weatherapi.com", "zipcode temperature"},
+		},
+		{
+			name:     "slow root keeps children",
+			parent:   context.Background(),
+			duration: time.Second,
+			expected: []string{"GET viacep.com.br", "GET api.weatherapi.com", "zipcode temperature"},
+		},
+		{
+			name:       "child error alone does not keep the trace",
+			parent:     context.Background(),
+			childError: true,
+			duration:   10 * time.Millisecond,
+		},
+		{
+			name:       "unsampled remote parent is decided locally",
+			parent:     remoteParent,
+			rootStatus: codes.Error,
+			duration:   10 * time.Millisecond,
+			expected:   []string{"GET viacep.com.br", "GET api.weatherapi.com", "zipcode temperature"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(NewRuleBasedSampler(sdktrace.ParentBased(sdktrace.NeverSample()))),
+				sdktrace.WithSpanProcessor(NewRuleBasedProcessor(recorder, 500*time.Millisecond)),
+			)
+			tracer := tp.Tracer("test")
+			start := time.Now()
+
+			ctx, root := tracer.Start(tt.parent, "zipcode temperature", trace.WithTimestamp(start))
+			_, viacep := tracer.Start(ctx, "GET viacep.com.br", trace.WithTimestamp(start))
+			if tt.childError {
+				viacep.SetStatus(codes.Error, "404 Not Found")
+			}
+			viacep.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+			_, weather := tracer.Start(ctx, "GET api.weatherapi.com", trace.WithTimestamp(start))
+			weather.End(trace.WithTimestamp(start.Add(2 * time.Millisecond)))
+			root.SetStatus(tt.rootStatus, "")
+			root.End(trace.WithTimestamp(start.Add(tt.duration)))
+
+			var names []string
+			for _, s := range recorder.Ended() {
+				assert.True(t, s.SpanContext().IsSampled())
+				assert.Equal(t, root.SpanContext().TraceID(), s.SpanContext().TraceID())
+				names = append(names, s.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestRuleBasedSampling_SampledTracePassesThrough(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewRuleBasedSampler(sdktrace.AlwaysSample())),
+		sdktrace.WithSpanProcessor(NewRuleBasedProcessor(recorder, 500*time.Millisecond)),
+	)
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "zipcode temperature")
+	_, child := tracer.Start(ctx, "GET viacep.com.br")
+	child.End()
+	assert.Len(t, recorder.Ended(), 1)
+	root.End()
+
+	assert.Len(t, recorder.Ended(), 2)
+}
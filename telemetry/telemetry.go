@@ -0,0 +1,130 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"sort"
+)
+
+// Setup configura os providers globais de traces e métricas e o propagator
+// a partir de cfg. A função devolvida encerra os providers, descarregando os
+// dados pendentes, e deve ser chamada antes do processo terminar, com um
+// contexto que ainda não tenha sido cancelado.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	cfg = cfg.withDefaults()
+
+	var flushFuncs, shutdownFuncs []func(context.Context) error
+	shutdown := func(ctx context.Context) error {
+		var err error
+		// Os spans pendentes no batch processor são enviados antes de tudo,
+		// para que uma demora no encerramento das métricas não os descarte
+		for _, flush := range flushFuncs {
+			err = errors.Join(err, flush(ctx))
+		}
+		flushFuncs = nil
+		for i := len(shutdownFuncs) - 1; i >= 0; i-- {
+			err = errors.Join(err, shutdownFuncs[i](ctx))
+		}
+		shutdownFuncs = nil
+		return err
+	}
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider, err := newTracerProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, err
+	}
+	flushFuncs = append(flushFuncs, tracerProvider.ForceFlush)
+	shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
+	otel.SetTracerProvider(tracerProvider)
+
+	meterProvider, err := newMeterProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, errors.Join(err, shutdown(ctx))
+	}
+	shutdownFuncs = append(shutdownFuncs, meterProvider.Shutdown)
+	otel.SetMeterProvider(meterProvider)
+
+	propagator, err := newPropagator(cfg)
+	if err != nil {
+		return nil, errors.Join(err, shutdown(ctx))
+	}
+	otel.SetTextMapPropagator(propagator)
+
+	return shutdown, nil
+}
+
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentName(cfg.Environment))
+	}
+
+	keys := make([]string, 0, len(cfg.Attributes))
+	for key := range cfg.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, attribute.String(key, cfg.Attributes[key]))
+	}
+
+	return resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+	)
+}
+
+func newTracerProvider(ctx context.Context, cfg Config, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(res),
+	}
+
+	exporter, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+		if cfg.Sampler == "rules" {
+			processor = NewRuleBasedProcessor(processor, cfg.SlowThreshold)
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+func newMeterProvider(ctx context.Context, cfg Config, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+
+	readers, err := newMetricReaders(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+
+	return sdkmetric.NewMeterProvider(opts...), nil
+}
@@ -0,0 +1,185 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName:     "test",
+		TracesExporter:  "none",
+		MetricsExporter: "none",
+	})
+	assert.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "span")
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_ShutdownFlushesSpans(t *testing.T) {
+	var exported atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	tests := []struct {
+		name     string
+		exporter string
+		endpoint string
+	}{
+		{name: "otlp with base url", exporter: "otlp", endpoint: collector.URL},
+		{name: "otlp with trailing slash", exporter: "otlp", endpoint: collector.URL + "/"},
+		{name: "otlp-http with host and port", exporter: "otlp-http", endpoint: strings.TrimPrefix(collector.URL, "http://")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exported.Store(0)
+			shutdown, err := Setup(context.Background(), Config{
+				ServiceName:     "test",
+				TracesExporter:  tt.exporter,
+				MetricsExporter: "none",
+				OTLPEndpoint:    tt.endpoint,
+				OTLPProtocol:    "http/protobuf",
+				OTLPInsecure:    true,
+			})
+			assert.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "span")
+			span.End()
+			assert.Zero(t, exported.Load())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			assert.NoError(t, shutdown(ctx))
+			assert.Equal(t, int32(1), exported.Load())
+		})
+	}
+}
+
+func TestOTLPEndpointURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		signalPath string
+		expected   string
+		ok         bool
+	}{
+		{name: "base url", endpoint: "http://otel-collector:4318", signalPath: "/v1/traces", expected: "http://otel-collector:4318/v1/traces", ok: true},
+		{name: "base url with path", endpoint: "https://collector.example.com/otlp/", signalPath: "/v1/metrics", expected: "https://collector.example.com/otlp/v1/metrics", ok: true},
+		{name: "grpc url", endpoint: "http://otel-collector:4317", expected: "http://otel-collector:4317", ok: true},
+		{name: "host and port", endpoint: "otel-collector:4317", signalPath: "/v1/traces"},
+		{name: "empty", endpoint: "", signalPath: "/v1/traces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := otlpEndpointURL(tt.endpoint, tt.signalPath)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSetup_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "unknown traces exporter",
+			cfg:  Config{ServiceName: "test", TracesExporter: "jaeger", MetricsExporter: "none"},
+		},
+		{
+			name: "unknown metrics exporter",
+			cfg:  Config{ServiceName: "test", TracesExporter: "none", MetricsExporter: "graphite"},
+		},
+		{
+			name: "unknown otlp protocol",
+			cfg:  Config{ServiceName: "test", TracesExporter: "otlp", OTLPProtocol: "http/json", MetricsExporter: "none"},
+		},
+		{
+			name: "unknown sampler",
+			cfg:  Config{ServiceName: "test", TracesExporter: "none", MetricsExporter: "none", Sampler: "sometimes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Setup(context.Background(), tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	res, err := newResource(context.Background(), Config{
+		ServiceName:    "serviceb",
+		ServiceVersion: "1.2.3",
+		Environment:    "production",
+		Attributes:     map[string]string{"cloud.region": "southamerica-east1"},
+	})
+	assert.NoError(t, err)
+
+	attrs := res.Set()
+	for _, want := range []attribute.KeyValue{
+		semconv.ServiceName("serviceb"),
+		semconv.ServiceVersion("1.2.3"),
+		semconv.DeploymentEnvironmentName("production"),
+		attribute.String("cloud.region", "southamerica-east1"),
+	} {
+		got, ok := attrs.Value(want.Key)
+		assert.True(t, ok, "missing attribute %s", want.Key)
+		assert.Equal(t, want.Value, got)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel-collector:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "30s")
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+
+	cfg := ConfigFromEnv("servicea")
+
+	assert.Equal(t, "servicea", cfg.ServiceName)
+	assert.Equal(t, "otlp", cfg.TracesExporter)
+	assert.Equal(t, "http://otel-collector:4317", cfg.OTLPEndpoint)
+	assert.Equal(t, "grpc", cfg.OTLPProtocol)
+	assert.True(t, cfg.OTLPInsecure)
+	assert.Equal(t, 30*time.Second, cfg.MetricInterval)
+	assert.Equal(t, "parentbased_traceidratio", cfg.Sampler)
+	assert.Equal(t, 0.25, cfg.SamplerArg)
+}
+
+func TestConfigFromEnv_Defaults(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "")
+
+	cfg := ConfigFromEnv("serviceb")
+
+	assert.Equal(t, "serviceb", cfg.ServiceName)
+	assert.True(t, cfg.OTLPInsecure)
+	assert.Equal(t, 1.0, cfg.SamplerArg)
+}